@@ -0,0 +1,66 @@
+package app
+
+import (
+	"fmt"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/export"
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/ui"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// animationExport renders every frame of a GIF and writes the animated export off the update
+// loop. Its events are read one per command: an exportProgressMsg for each rendered frame and
+// an exportDoneMsg last.
+type animationExport struct {
+	events chan tea.Msg
+}
+
+type exportProgressMsg struct {
+	request         ui.ExportRequestMsg
+	rendered, total int
+}
+
+type exportDoneMsg struct {
+	request ui.ExportRequestMsg
+	err     error
+}
+
+// startAnimationExport renders source with options and writes it as request asks.
+func startAnimationExport(request ui.ExportRequestMsg, source string, options services.RenderOptions) *animationExport {
+	e := &animationExport{events: make(chan tea.Msg)}
+	go func() {
+		defer close(e.events)
+		animation, err := services.RenderAnimationProgress(source, options, func(rendered, total int) {
+			e.events <- exportProgressMsg{request: request, rendered: rendered, total: total}
+		})
+		if err == nil {
+			err = export.WriteAnimationFile(request.Path, animation, export.Format(request.Format), request.Overwrite)
+		}
+		e.events <- exportDoneMsg{request: request, err: err}
+	}()
+	return e
+}
+
+// nextEventCmd waits for the next event of the export.
+func (e *animationExport) nextEventCmd() tea.Cmd {
+	return func() tea.Msg {
+		return <-e.events
+	}
+}
+
+func (m *MezzotoneModel) handleExportProgress(msg exportProgressMsg) tea.Cmd {
+	if m.exporting == nil {
+		return nil
+	}
+	m.updateMessageViewPortContent(
+		fmt.Sprintf("Exporting %s to %s: frame %d/%d", msg.request.Format, msg.request.Path, msg.rendered, msg.total),
+		false,
+	)
+	return m.exporting.nextEventCmd()
+}
+
+func (m *MezzotoneModel) handleExportDone(msg exportDoneMsg) {
+	m.exporting = nil
+	m.finishExport(msg.request, msg.err)
+}
@@ -1,51 +1,82 @@
 package app
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/pkg/ascii"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// buildRenderHelpText lists the active key bindings of every pane, followed by what the
+// render options do.
+func buildRenderHelpText(keys KeyMap) string {
+	h := help.New()
+	h.Styles.FullKey = lipgloss.NewStyle().Bold(true)
+	h.Styles.FullDesc = lipgloss.NewStyle()
+	section := func(title string, bindings ...key.Binding) string {
+		lines := strings.Split(h.FullHelpView([][]key.Binding{bindings}), "\n")
+		for i, line := range lines {
+			lines[i] = "  " + strings.TrimRight(line, " ")
+		}
+		return title + "\n" + strings.Join(lines, "\n") + "\n"
+	}
 
-func buildRenderHelpText() string {
 	return strings.Join([]string{
 		"MEZZOTONE HELP",
 		"",
 		"Controls",
 		"",
-		"Global",
-		"  h              Toggle this help",
-		"  esc            Back / close help / quit from file picker",
-		"  ctrl+c         Quit",
-		"",
-		"File Picker",
-		"  j/k or up/down Navigate options",
-		"  pgdown         Go To Bottom",
-		"  pgup           Go To up",
-		"  enter/right    Open directory or select image",
-		"  left/backspace Go back directory",
-		"",
-		"Render Options",
-		"  j/k or up/down Navigate options",
-		"  pgdown         Go To Bottom",
-		"  pgup           Go To up",
-		"  enter          Edit numeric fields / change enum / confirm",
-		"  space          Toggle bool values",
-		"  left/right     Change enum values",
-		"  esc            Cancel edit or go back to file picker",
-		"",
-		"Render View",
-		"  arrows         Scroll output/help",
-		"  h              Hide help",
+		section("Global", keys.Help, keys.Back, keys.Quit),
+		"Mouse",
+		"  click          Highlight a file, click again to open it",
+		"  click          Select an option; bools and enums change at once",
+		"  click CONFIRM  Render",
+		"  wheel          Scroll the render, shift+wheel scrolls sideways",
+		"  click a tab    Switch panes when the terminal is too narrow for all of them",
 		"",
+		section("File Picker",
+			keys.PickerUp, keys.PickerDown, keys.PickerTop, keys.PickerBottom,
+			keys.PickerOpen, keys.PickerBack, keys.PickerRecent,
+		),
+		section("Render Options",
+			keys.OptionsUp, keys.OptionsDown, keys.OptionsDecrease, keys.OptionsIncrease,
+			keys.OptionsToggle, keys.OptionsEdit, keys.OptionsTop, keys.OptionsBottom,
+			keys.SavePreset, keys.Undo, keys.Redo,
+		),
+		section("Render View",
+			keys.ScrollUp, keys.ScrollDown, keys.ScrollLeft, keys.ScrollRight,
+			keys.PageUp, keys.PageDown, keys.HalfPageUp, keys.HalfPageDown,
+			keys.Top, keys.Bottom, keys.PageLeft, keys.PageRight,
+			keys.HalfPageLeft, keys.HalfPageRight, keys.FirstColumn, keys.LastColumn,
+			keys.Rulers, keys.Jump, keys.Find, keys.FindNext, keys.FindPrevious,
+			keys.Copy, keys.CopyANSI, keys.Split, keys.Pin, keys.Compare, keys.Flip,
+			keys.Undo, keys.Redo, keys.Export, keys.Fullscreen,
+		),
+		section("Dialogs",
+			keys.DialogNext, keys.DialogPrevious, keys.DialogLeft, keys.DialogRight,
+			keys.DialogPress, keys.DialogYes, keys.DialogNo, keys.Back,
+		),
 		"Render Option Explanations",
 		"",
+		"Preset",
+		"  Named options from the config file. Editing any option switches",
+		"  back to (custom).",
+		"",
 		"Text Size",
-		"  Character cell width in pixels. Larger values reduce detail.",
+		"  Character cell width in pixels (1..100). Larger values reduce detail.",
 		"",
 		"Font Aspect",
-		"  Character height ratio vs width to match terminal font shape.",
+		fmt.Sprintf("  Character height ratio vs width to match terminal font shape (%g..%g).", ascii.MinFontAspect, ascii.MaxFontAspect),
 		"",
 		"Directional Render",
 		"  Uses edge direction to place oriented glyphs on strong edges.",
 		"",
 		"Edge Threshold",
-		"  Edge cutoff (0..1) for directional glyph replacement.",
+		"  Edge cutoff (0..1) for directional glyph replacement. Shown while",
+		"  Directional Render is on.",
 		"",
 		"Reverse Chars",
 		"  Inverts ramp mapping for terminals/themes where output looks",
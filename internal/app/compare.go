@@ -0,0 +1,89 @@
+package app
+
+import (
+	"fmt"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/ui"
+)
+
+// compareState is render A, pinned for an A/B comparison against the current render B.
+type compareState struct {
+	pinned  *services.RenderResult
+	content string
+	// values are the render options A was made with, keyed like the settings items.
+	values map[string]string
+	// flipped shows A in the render pane instead of B.
+	flipped bool
+}
+
+func settingValues(items []ui.SettingItem) map[string]string {
+	values := make(map[string]string, len(items))
+	for _, item := range items {
+		if item.Key == presetKey {
+			continue
+		}
+		values[item.Key] = item.Value
+	}
+	return values
+}
+
+// displayedRender is what the render pane shows: B, or A while flipped.
+func (m *MezzotoneModel) displayedRender() string {
+	if m.compare.flipped && m.compare.pinned != nil {
+		return m.compare.content
+	}
+	return m.renderContent
+}
+
+// togglePin pins the current render as A, or drops the pinned render.
+func (m *MezzotoneModel) togglePin() {
+	if m.compare.pinned != nil {
+		if m.split.mode == splitPinned {
+			m.setSplitMode(splitOff)
+		}
+		m.compare = compareState{}
+		m.renderSettings.SetCompareValues(nil)
+		m.renderView.SetContent(m.displayedRender())
+		m.updateMessageViewPortContent("Unpinned render A", false)
+		return
+	}
+
+	m.compare = compareState{
+		pinned:  m.renderResult,
+		content: m.renderContent,
+		values:  m.renderedValues,
+	}
+	m.renderSettings.SetCompareValues(m.renderedValues)
+	m.updateMessageViewPortContent(
+		fmt.Sprintf("Pinned as A. Render again, then %s: side by side, %s: flip", m.keys.Compare.Help().Key, m.keys.Flip.Help().Key),
+		false,
+	)
+}
+
+func (m *MezzotoneModel) toggleCompareSplit() {
+	if m.compare.pinned == nil {
+		m.updateMessageViewPortContent(fmt.Sprintf("⚠ Pin a render with %s first", m.keys.Pin.Help().Key), true)
+		return
+	}
+	m.setSplitMode(splitPinned)
+	if m.split.mode == splitPinned {
+		m.updateMessageViewPortContent("A (pinned) │ B (current)", false)
+	} else {
+		m.updateMessageViewPortContent("Comparison view off", false)
+	}
+}
+
+func (m *MezzotoneModel) flipCompare() {
+	if m.compare.pinned == nil {
+		m.updateMessageViewPortContent(fmt.Sprintf("⚠ Pin a render with %s first", m.keys.Pin.Help().Key), true)
+		return
+	}
+	m.compare.flipped = !m.compare.flipped
+	m.renderView.SetContent(m.displayedRender())
+	if m.compare.flipped {
+		m.updateMessageViewPortContent("Showing A (pinned)", false)
+	} else {
+		m.updateMessageViewPortContent("Showing B (current)", false)
+	}
+}
@@ -0,0 +1,207 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/charmbracelet/bubbles/filepicker"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// filePicker is the bubbles file picker with its cursor kept track of. The picker does not tell
+// which entry is highlighted or which ones are on screen, so filePicker lists the directory
+// itself and moves a copy of the cursor the way the picker moves its own.
+type filePicker struct {
+	filepicker.Model
+
+	// dir is the directory entries were listed from.
+	dir     string
+	entries []os.DirEntry
+	// selected is the highlighted entry and min to max the entries on screen, as in the picker.
+	selected int
+	min, max int
+	// views are the cursors of the parent directories, restored on the way back up.
+	views []pickerView
+}
+
+type pickerView struct {
+	selected, min, max int
+}
+
+// pickerActionKeys are the keys filePicker presses for the mouse. The picker only moves on
+// keys, so they stand in for the user's bindings while it handles them and the mouse works
+// however the keys are bound.
+var pickerActionKeys = filepicker.KeyMap{
+	Down:   key.NewBinding(key.WithKeys("down")),
+	Up:     key.NewBinding(key.WithKeys("up")),
+	Open:   key.NewBinding(key.WithKeys("enter")),
+	Select: key.NewBinding(key.WithKeys("enter")),
+}
+
+func newFilePicker() filePicker {
+	return filePicker{Model: filepicker.New()}
+}
+
+// Init lists CurrentDirectory, which the picker does asynchronously too.
+func (p *filePicker) Init() tea.Cmd {
+	p.listDirectory()
+	return p.Model.Init()
+}
+
+func (p *filePicker) SetHeight(height int) {
+	p.Model.SetHeight(height)
+	if p.max > p.Height-1 {
+		p.max = p.min + p.Height - 1
+	}
+}
+
+func (p filePicker) Update(msg tea.Msg) (filePicker, tea.Cmd) {
+	previousDir := p.CurrentDirectory
+
+	var cmd tea.Cmd
+	p.Model, cmd = p.Model.Update(msg)
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.max = p.Height - 1
+	case tea.KeyMsg:
+		p.moveCursor(msg, p.CurrentDirectory != previousDir)
+	}
+	if p.CurrentDirectory != p.dir {
+		p.listDirectory()
+	}
+	return p, cmd
+}
+
+// moveCursor follows the picker's own handling of msg. entered is whether it opened a
+// directory.
+func (p *filePicker) moveCursor(msg tea.KeyMsg, entered bool) {
+	keys, n := p.KeyMap, len(p.entries)
+	switch {
+	case key.Matches(msg, keys.GoToTop):
+		p.selected, p.min, p.max = 0, 0, p.Height-1
+	case key.Matches(msg, keys.GoToLast):
+		p.selected, p.min, p.max = n-1, n-p.Height, n-1
+	case key.Matches(msg, keys.Down):
+		p.selected = min(p.selected+1, n-1)
+		if p.selected > p.max {
+			p.min++
+			p.max++
+		}
+	case key.Matches(msg, keys.Up):
+		p.selected = max(p.selected-1, 0)
+		if p.selected < p.min {
+			p.min--
+			p.max--
+		}
+	case key.Matches(msg, keys.PageDown):
+		p.selected = min(p.selected+p.Height, n-1)
+		p.min += p.Height
+		p.max += p.Height
+		if p.max >= n {
+			p.max = n - 1
+			p.min = p.max - p.Height
+		}
+	case key.Matches(msg, keys.PageUp):
+		p.selected = max(p.selected-p.Height, 0)
+		p.min -= p.Height
+		p.max -= p.Height
+		if p.min < 0 {
+			p.min = 0
+			p.max = p.min + p.Height
+		}
+	case key.Matches(msg, keys.Back):
+		if len(p.views) > 0 {
+			v := p.views[len(p.views)-1]
+			p.views = p.views[:len(p.views)-1]
+			p.selected, p.min, p.max = v.selected, v.min, v.max
+		} else {
+			p.selected, p.min, p.max = 0, 0, p.Height-1
+		}
+	case key.Matches(msg, keys.Open) && entered:
+		p.views = append(p.views, pickerView{p.selected, p.min, p.max})
+		p.selected, p.min, p.max = 0, 0, p.Height-1
+	}
+}
+
+// moveTo highlights entry, or the nearest entry there is.
+func (p filePicker) moveTo(entry int) filePicker {
+	msg := tea.KeyMsg{Type: tea.KeyDown}
+	if entry < p.selected {
+		msg.Type = tea.KeyUp
+	}
+	keys := p.KeyMap
+	p.KeyMap = pickerActionKeys
+	for entry != p.selected {
+		before := p.selected
+		p, _ = p.Update(msg)
+		if p.selected == before {
+			break
+		}
+	}
+	p.KeyMap = keys
+	return p
+}
+
+// open opens the highlighted directory or selects the highlighted file. path is the selected
+// file, if any, and allowed whether its type is one of AllowedTypes.
+func (p filePicker) open() (_ filePicker, cmd tea.Cmd, path string, allowed bool) {
+	msg := tea.KeyMsg{Type: tea.KeyEnter}
+	keys := p.KeyMap
+	p.KeyMap = pickerActionKeys
+	p, cmd = p.Update(msg)
+	if ok, selected := p.DidSelectFile(msg); ok {
+		path, allowed = selected, true
+	} else if ok, selected := p.DidSelectDisabledFile(msg); ok {
+		path = selected
+	}
+	p.KeyMap = keys
+	return p, cmd, path, allowed
+}
+
+// listDirectory lists CurrentDirectory like the picker: directories first, by name, and
+// without hidden files unless they are shown. A directory that cannot be read keeps the
+// previous entries, as the picker does.
+func (p *filePicker) listDirectory() {
+	p.dir = p.CurrentDirectory
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].IsDir() == entries[j].IsDir() {
+			return entries[i].Name() < entries[j].Name()
+		}
+		return entries[i].IsDir()
+	})
+	if !p.ShowHidden {
+		shown := entries[:0]
+		for _, e := range entries {
+			if hidden, _ := filepicker.IsHidden(e.Name()); !hidden {
+				shown = append(shown, e)
+			}
+		}
+		entries = shown
+	}
+	p.entries = entries
+	p.max = max(p.max, p.Height-1)
+}
+
+// highlighted is the path of the entry under the cursor, or "" in an empty directory.
+func (p *filePicker) highlighted() string {
+	if p.selected < 0 || p.selected >= len(p.entries) {
+		return ""
+	}
+	return filepath.Join(p.dir, p.entries[p.selected].Name())
+}
+
+// entryAt is the index of the entry on row of the list, counted from its top.
+func (p *filePicker) entryAt(row int) (int, bool) {
+	i := max(0, p.min) + row
+	if row < 0 || i > p.max || i >= len(p.entries) {
+		return 0, false
+	}
+	return i, true
+}
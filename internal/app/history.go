@@ -0,0 +1,141 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/ui"
+)
+
+// maxHistory bounds the undo history; every entry may hold a whole render.
+const maxHistory = 50
+
+// historyEntry is a snapshot of every settings item value, in item order, and the render made
+// with exactly those values, if any.
+type historyEntry struct {
+	values []string
+	render *services.RenderResult
+}
+
+// optionHistory is the undo/redo stack of render option changes. entries[index] is the current
+// state; undo moves towards the start and redo back towards the end.
+type optionHistory struct {
+	entries []historyEntry
+	index   int
+}
+
+func itemValues(items []ui.SettingItem) []string {
+	values := make([]string, len(items))
+	for i, item := range items {
+		values[i] = item.Value
+	}
+	return values
+}
+
+// record adds the change from before to after, dropping everything that could be redone.
+func (h *optionHistory) record(before, after []string) {
+	if slices.Equal(before, after) {
+		return
+	}
+	if len(h.entries) == 0 {
+		h.entries = []historyEntry{{values: before}}
+	}
+	h.entries = append(h.entries[:h.index+1], historyEntry{values: after})
+	if len(h.entries) > maxHistory {
+		h.entries = h.entries[len(h.entries)-maxHistory:]
+	}
+	h.index = len(h.entries) - 1
+}
+
+func (h *optionHistory) undo() (historyEntry, bool) {
+	if h.index == 0 || len(h.entries) == 0 {
+		return historyEntry{}, false
+	}
+	h.index--
+	return h.entries[h.index], true
+}
+
+func (h *optionHistory) redo() (historyEntry, bool) {
+	if h.index >= len(h.entries)-1 {
+		return historyEntry{}, false
+	}
+	h.index++
+	return h.entries[h.index], true
+}
+
+// storeRender caches result on the current entry, which must hold values.
+func (h *optionHistory) storeRender(values []string, result *services.RenderResult) {
+	if len(h.entries) == 0 {
+		h.entries = []historyEntry{{values: values}}
+		h.index = 0
+	}
+	if current := &h.entries[h.index]; slices.Equal(current.values, values) {
+		current.render = result
+	}
+}
+
+// cachedRender returns a render made with values anywhere in the history.
+func (h *optionHistory) cachedRender(values []string) *services.RenderResult {
+	for _, entry := range h.entries {
+		if entry.render != nil && slices.Equal(entry.values, values) {
+			return entry.render
+		}
+	}
+	return nil
+}
+
+// dropRenders forgets the cached renders, which belong to the previously selected file.
+func (h *optionHistory) dropRenders() {
+	for i := range h.entries {
+		h.entries[i].render = nil
+	}
+}
+
+// position describes the current entry for the status message, e.g. "3/7".
+func (h *optionHistory) position() string {
+	return fmt.Sprintf("%d/%d", h.index+1, len(h.entries))
+}
+
+func (m *MezzotoneModel) undoOptions() {
+	entry, ok := m.history.undo()
+	if !ok {
+		m.updateMessageViewPortContent("Nothing to undo", false)
+		return
+	}
+	m.restoreHistoryEntry(entry, "Undo")
+}
+
+func (m *MezzotoneModel) redoOptions() {
+	entry, ok := m.history.redo()
+	if !ok {
+		m.updateMessageViewPortContent("Nothing to redo", false)
+		return
+	}
+	m.restoreHistoryEntry(entry, "Redo")
+}
+
+// restoreHistoryEntry puts the snapshot back into the settings panel and shows its render
+// right away when one was cached.
+func (m *MezzotoneModel) restoreHistoryEntry(entry historyEntry, action string) {
+	for i := range m.renderSettings.Items {
+		if i < len(entry.values) {
+			m.renderSettings.Items[i].Value = entry.values[i]
+		}
+	}
+
+	if entry.render == nil || m.playback != nil {
+		m.updateMessageViewPortContent(fmt.Sprintf("%s %s: options restored, confirm to render", action, m.history.position()), false)
+		return
+	}
+	m.renderResult = entry.render
+	m.renderContent = entry.render.String()
+	m.renderedValues = settingValues(m.renderSettings.Items)
+	if !m.helpVisible {
+		m.renderView.SetContent(m.displayedRender())
+	}
+	if m.split.mode == splitSource {
+		m.updateSourceView(m.split.image)
+	}
+	m.updateMessageViewPortContent(fmt.Sprintf("%s %s: showing cached render", action, m.history.position()), false)
+}
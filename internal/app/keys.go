@@ -0,0 +1,349 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/ui"
+	"github.com/charmbracelet/bubbles/filepicker"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+)
+
+// KeyMap is every key binding of the TUI. The global bindings work in every pane, the others
+// only in the pane their name starts with.
+type KeyMap struct {
+	Help, Back, Quit key.Binding
+
+	PickerUp, PickerDown, PickerTop, PickerBottom key.Binding
+	PickerBack, PickerOpen, PickerRecent          key.Binding
+
+	OptionsUp, OptionsDown, OptionsDecrease, OptionsIncrease key.Binding
+	OptionsToggle, OptionsEdit, OptionsTop, OptionsBottom    key.Binding
+	SavePreset                                               key.Binding
+
+	// Undo and Redo work in the options and the render view.
+	Undo, Redo key.Binding
+
+	ScrollUp, ScrollDown, ScrollLeft, ScrollRight key.Binding
+	PageUp, PageDown, HalfPageUp, HalfPageDown    key.Binding
+	Top, Bottom, FirstColumn, LastColumn          key.Binding
+	PageLeft, PageRight, HalfPageLeft             key.Binding
+	HalfPageRight, Rulers, Jump                   key.Binding
+	Copy, CopyANSI, Split, Pin, Compare, Flip     key.Binding
+	Export, Fullscreen                            key.Binding
+	Find, FindNext, FindPrevious                  key.Binding
+
+	// The dialog keys work in the export dialog, confirmations, prompts and error details,
+	// which Back cancels.
+	DialogNext, DialogPrevious, DialogLeft, DialogRight key.Binding
+	DialogPress, DialogYes, DialogNo                    key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Help: key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "Toggle this help")),
+		Back: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "Back / cancel edit / ask to quit from file picker")),
+		Quit: key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("ctrl+c", "Quit")),
+
+		PickerUp:     key.NewBinding(key.WithKeys("k", "up"), key.WithHelp("k/up", "Previous file")),
+		PickerDown:   key.NewBinding(key.WithKeys("j", "down"), key.WithHelp("j/down", "Next file")),
+		PickerTop:    key.NewBinding(key.WithKeys("K", "pgup"), key.WithHelp("K/pgup", "Go to top")),
+		PickerBottom: key.NewBinding(key.WithKeys("J", "pgdown"), key.WithHelp("J/pgdown", "Go to bottom")),
+		PickerBack:   key.NewBinding(key.WithKeys("left", "backspace"), key.WithHelp("left/backspace", "Go back directory")),
+		PickerOpen:   key.NewBinding(key.WithKeys("right", "enter"), key.WithHelp("right/enter", "Open directory or select image")),
+		PickerRecent: key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "Switch between recent files and the directory")),
+
+		OptionsUp:       key.NewBinding(key.WithKeys("k", "up"), key.WithHelp("k/up", "Previous option")),
+		OptionsDown:     key.NewBinding(key.WithKeys("j", "down"), key.WithHelp("j/down", "Next option")),
+		OptionsDecrease: key.NewBinding(key.WithKeys("left"), key.WithHelp("left", "Step down a number, enum or preset / fold a group")),
+		OptionsIncrease: key.NewBinding(key.WithKeys("right"), key.WithHelp("right", "Step up a number, enum or preset / unfold a group")),
+		OptionsToggle:   key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "Toggle bools and list options")),
+		OptionsEdit:     key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "Edit / toggle / fold / confirm")),
+		OptionsTop:      key.NewBinding(key.WithKeys("pgup"), key.WithHelp("pgup", "Go to the first option")),
+		OptionsBottom:   key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("pgdown", "Go to CONFIRM")),
+		SavePreset:      key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("ctrl+s", "Save current options as a preset")),
+
+		Undo: key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "Undo option changes, showing cached renders")),
+		Redo: key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("ctrl+r", "Redo option changes")),
+
+		ScrollUp:      key.NewBinding(key.WithKeys("up"), key.WithHelp("up", "Scroll up")),
+		ScrollDown:    key.NewBinding(key.WithKeys("down"), key.WithHelp("down", "Scroll down")),
+		ScrollLeft:    key.NewBinding(key.WithKeys("left"), key.WithHelp("left", "Scroll left")),
+		ScrollRight:   key.NewBinding(key.WithKeys("right"), key.WithHelp("right", "Scroll right")),
+		PageUp:        key.NewBinding(key.WithKeys("pgup"), key.WithHelp("pgup", "Page up")),
+		PageDown:      key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("pgdown", "Page down")),
+		HalfPageUp:    key.NewBinding(key.WithKeys("ctrl+u"), key.WithHelp("ctrl+u", "Half page up")),
+		HalfPageDown:  key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "Half page down")),
+		Top:           key.NewBinding(key.WithKeys("home", "g"), key.WithHelp("home/g", "Go to the first row")),
+		Bottom:        key.NewBinding(key.WithKeys("end", "G"), key.WithHelp("end/G", "Go to the last row")),
+		FirstColumn:   key.NewBinding(key.WithKeys("0"), key.WithHelp("0", "Go to the first column")),
+		LastColumn:    key.NewBinding(key.WithKeys("$"), key.WithHelp("$", "Go to the last column")),
+		PageLeft:      key.NewBinding(key.WithKeys("["), key.WithHelp("[", "Page left")),
+		PageRight:     key.NewBinding(key.WithKeys("]"), key.WithHelp("]", "Page right")),
+		HalfPageLeft:  key.NewBinding(key.WithKeys("{"), key.WithHelp("{", "Half page left")),
+		HalfPageRight: key.NewBinding(key.WithKeys("}"), key.WithHelp("}", "Half page right")),
+		Rulers:        key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "Toggle row and column rulers")),
+		Jump:          key.NewBinding(key.WithKeys(":"), key.WithHelp(":", "Jump to row,column")),
+		Copy:          key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "Copy render to clipboard")),
+		CopyANSI:      key.NewBinding(key.WithKeys("C"), key.WithHelp("C", "Copy render with ANSI colors")),
+		Split:         key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "Toggle side-by-side source and render")),
+		Pin:           key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "Pin render as A for comparison / unpin")),
+		Compare:       key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "Toggle pinned A next to current render B")),
+		Flip:          key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "Flip between pinned A and current B")),
+		Export:        key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "Save render to file (TEXT, ANSI, HTML, SVG, PNG, JSON)")),
+		Fullscreen:    key.NewBinding(key.WithKeys("z"), key.WithHelp("z", "Toggle full-screen render")),
+		Find:          key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "Find text in the render")),
+		FindNext:      key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "Next match")),
+		FindPrevious:  key.NewBinding(key.WithKeys("N"), key.WithHelp("N", "Previous match")),
+
+		DialogNext:     key.NewBinding(key.WithKeys("tab", "down"), key.WithHelp("tab/down", "Next input or button")),
+		DialogPrevious: key.NewBinding(key.WithKeys("shift+tab", "up"), key.WithHelp("shift+tab/up", "Previous input or button")),
+		DialogLeft:     key.NewBinding(key.WithKeys("left", "h"), key.WithHelp("left/h", "Previous button")),
+		DialogRight:    key.NewBinding(key.WithKeys("right", "l"), key.WithHelp("right/l", "Next button")),
+		DialogPress:    key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "Press the focused button")),
+		DialogYes:      key.NewBinding(key.WithKeys("y", "Y"), key.WithHelp("y", "Answer yes")),
+		DialogNo:       key.NewBinding(key.WithKeys("n", "N"), key.WithHelp("n", "Answer no")),
+	}
+}
+
+// keyScope is the set of panes a binding works in.
+type keyScope int
+
+const (
+	scopePicker keyScope = 1 << iota
+	scopeOptions
+	scopeRender
+	// scopeDialog is the export dialog, confirmations and prompts, which take the keys of
+	// every pane.
+	scopeDialog
+
+	scopeGlobal = scopePicker | scopeOptions | scopeRender
+)
+
+type namedBinding struct {
+	name    string
+	scope   keyScope
+	binding *key.Binding
+}
+
+// actions names the bindings for the keys section of the config file.
+func (k *KeyMap) actions() []namedBinding {
+	return []namedBinding{
+		{"help", scopeGlobal, &k.Help},
+		{"back", scopeGlobal | scopeDialog, &k.Back},
+		{"quit", scopeGlobal | scopeDialog, &k.Quit},
+
+		{"picker.up", scopePicker, &k.PickerUp},
+		{"picker.down", scopePicker, &k.PickerDown},
+		{"picker.top", scopePicker, &k.PickerTop},
+		{"picker.bottom", scopePicker, &k.PickerBottom},
+		{"picker.back", scopePicker, &k.PickerBack},
+		{"picker.open", scopePicker, &k.PickerOpen},
+		{"picker.recent", scopePicker, &k.PickerRecent},
+
+		{"options.up", scopeOptions, &k.OptionsUp},
+		{"options.down", scopeOptions, &k.OptionsDown},
+		{"options.decrease", scopeOptions, &k.OptionsDecrease},
+		{"options.increase", scopeOptions, &k.OptionsIncrease},
+		{"options.toggle", scopeOptions, &k.OptionsToggle},
+		{"options.edit", scopeOptions, &k.OptionsEdit},
+		{"options.top", scopeOptions, &k.OptionsTop},
+		{"options.bottom", scopeOptions, &k.OptionsBottom},
+		{"options.savePreset", scopeOptions, &k.SavePreset},
+
+		{"undo", scopeOptions | scopeRender, &k.Undo},
+		{"redo", scopeOptions | scopeRender, &k.Redo},
+
+		{"render.up", scopeRender, &k.ScrollUp},
+		{"render.down", scopeRender, &k.ScrollDown},
+		{"render.left", scopeRender, &k.ScrollLeft},
+		{"render.right", scopeRender, &k.ScrollRight},
+		{"render.pageUp", scopeRender, &k.PageUp},
+		{"render.pageDown", scopeRender, &k.PageDown},
+		{"render.halfPageUp", scopeRender, &k.HalfPageUp},
+		{"render.halfPageDown", scopeRender, &k.HalfPageDown},
+		{"render.top", scopeRender, &k.Top},
+		{"render.bottom", scopeRender, &k.Bottom},
+		{"render.firstColumn", scopeRender, &k.FirstColumn},
+		{"render.lastColumn", scopeRender, &k.LastColumn},
+		{"render.pageLeft", scopeRender, &k.PageLeft},
+		{"render.pageRight", scopeRender, &k.PageRight},
+		{"render.halfPageLeft", scopeRender, &k.HalfPageLeft},
+		{"render.halfPageRight", scopeRender, &k.HalfPageRight},
+		{"render.rulers", scopeRender, &k.Rulers},
+		{"render.jump", scopeRender, &k.Jump},
+		{"render.find", scopeRender, &k.Find},
+		{"render.findNext", scopeRender, &k.FindNext},
+		{"render.findPrevious", scopeRender, &k.FindPrevious},
+		{"render.copy", scopeRender, &k.Copy},
+		{"render.copyANSI", scopeRender, &k.CopyANSI},
+		{"render.split", scopeRender, &k.Split},
+		{"render.pin", scopeRender, &k.Pin},
+		{"render.compare", scopeRender, &k.Compare},
+		{"render.flip", scopeRender, &k.Flip},
+		{"render.export", scopeRender, &k.Export},
+		{"render.fullscreen", scopeRender, &k.Fullscreen},
+
+		{"dialog.next", scopeDialog, &k.DialogNext},
+		{"dialog.previous", scopeDialog, &k.DialogPrevious},
+		{"dialog.left", scopeDialog, &k.DialogLeft},
+		{"dialog.right", scopeDialog, &k.DialogRight},
+		{"dialog.press", scopeDialog, &k.DialogPress},
+		{"dialog.yes", scopeDialog, &k.DialogYes},
+		{"dialog.no", scopeDialog, &k.DialogNo},
+	}
+}
+
+// NewKeyMap rebinds the default keys with overrides from the config file, by action name.
+// An empty list unbinds the action. Unknown actions and keys bound to two actions of the same
+// pane are reported together.
+func NewKeyMap(overrides map[string][]string) (KeyMap, error) {
+	keys := DefaultKeyMap()
+	actions := keys.actions()
+
+	var errs []error
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		i := slices.IndexFunc(actions, func(a namedBinding) bool { return a.name == name })
+		if i < 0 {
+			errs = append(errs, fmt.Errorf("keys: unknown action %q", name))
+			continue
+		}
+		binding, bound := actions[i].binding, overrides[name]
+		if len(bound) == 0 {
+			binding.SetKeys()
+			binding.SetEnabled(false)
+			continue
+		}
+		binding.SetKeys(bound...)
+		binding.SetHelp(helpKeys(bound), binding.Help().Desc)
+	}
+
+	if err := keys.Conflicts(); err != nil {
+		errs = append(errs, err)
+	}
+	return keys, errors.Join(errs...)
+}
+
+// Conflicts reports every key that is bound to two actions working in the same pane.
+func (k *KeyMap) Conflicts() error {
+	actions := k.actions()
+	var errs []error
+	for i, a := range actions {
+		for _, b := range actions[i+1:] {
+			if a.scope&b.scope == 0 {
+				continue
+			}
+			for _, bound := range a.binding.Keys() {
+				if slices.Contains(b.binding.Keys(), bound) {
+					errs = append(errs, fmt.Errorf("keys: %s is bound to both %s and %s", helpKeys([]string{bound}), a.name, b.name))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// helpKeys is how keys are shown in the help, with the space bar spelled out.
+func helpKeys(keys []string) string {
+	shown := make([]string, len(keys))
+	for i, k := range keys {
+		if k == " " {
+			k = "space"
+		}
+		shown[i] = k
+	}
+	return strings.Join(shown, "/")
+}
+
+// filePicker is the keymap of the file picker. Open both opens directories and selects files.
+func (k *KeyMap) filePicker() filepicker.KeyMap {
+	return filepicker.KeyMap{
+		GoToTop:  k.PickerTop,
+		GoToLast: k.PickerBottom,
+		Down:     k.PickerDown,
+		Up:       k.PickerUp,
+		Back:     k.PickerBack,
+		Open:     k.PickerOpen,
+		Select:   k.PickerOpen,
+	}
+}
+
+// settings is the keymap of the settings panel, which cancels edits with the global Back key.
+func (k *KeyMap) settings() ui.SettingsKeyMap {
+	return ui.SettingsKeyMap{
+		Up:       k.OptionsUp,
+		Down:     k.OptionsDown,
+		Decrease: k.OptionsDecrease,
+		Increase: k.OptionsIncrease,
+		Toggle:   k.OptionsToggle,
+		Edit:     k.OptionsEdit,
+		Cancel:   k.Back,
+	}
+}
+
+// withDesc is b with the short description desc, for the hint lines of the dialogs.
+func withDesc(b key.Binding, desc string) key.Binding {
+	b.SetHelp(b.Help().Key, desc)
+	return b
+}
+
+// modal is the keymap of the dialogs, with the short help their hint line shows.
+func (k *KeyMap) modal() ui.ModalKeyMap {
+	return ui.ModalKeyMap{
+		Next:   withDesc(k.DialogNext, "move"),
+		Prev:   k.DialogPrevious,
+		Left:   k.DialogLeft,
+		Right:  k.DialogRight,
+		Press:  withDesc(k.DialogPress, "select"),
+		Cancel: withDesc(k.Back, "cancel"),
+		Yes:    k.DialogYes,
+		No:     k.DialogNo,
+	}
+}
+
+// exportDialog is the keymap of the export dialog, which shares the dialog keys.
+func (k *KeyMap) exportDialog() ui.ExportDialogKeyMap {
+	return ui.ExportDialogKeyMap{
+		Next:       withDesc(k.DialogNext, "switch field"),
+		Prev:       k.DialogPrevious,
+		PrevFormat: k.DialogLeft,
+		NextFormat: withDesc(k.DialogRight, "format"),
+		Save:       withDesc(k.DialogPress, "save"),
+		Cancel:     withDesc(k.Back, "cancel"),
+	}
+}
+
+func (k *KeyMap) viewport() viewport.KeyMap {
+	return viewport.KeyMap{
+		PageDown:     k.PageDown,
+		PageUp:       k.PageUp,
+		HalfPageUp:   k.HalfPageUp,
+		HalfPageDown: k.HalfPageDown,
+		Up:           k.ScrollUp,
+		Down:         k.ScrollDown,
+		Left:         k.ScrollLeft,
+		Right:        k.ScrollRight,
+	}
+}
+
+// SetKeyMap replaces the key bindings of every pane.
+func (m *MezzotoneModel) SetKeyMap(keys KeyMap) {
+	m.keys = keys
+	m.filePicker.KeyMap = keys.filePicker()
+	m.renderSettings.KeyMap = keys.settings()
+	m.modal.KeyMap = keys.modal()
+	m.exportDialog.KeyMap = keys.exportDialog()
+	m.renderView.KeyMap = keys.viewport()
+	m.split.view.KeyMap = keys.viewport()
+	if m.helpVisible {
+		m.renderView.SetContent(buildRenderHelpText(m.keys))
+	}
+}
@@ -0,0 +1,256 @@
+package app
+
+import (
+	"strings"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/termtext"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type layoutMode int
+
+const (
+	// layoutWide puts the message, file picker and settings in a column left of the render.
+	layoutWide layoutMode = iota
+	// layoutStacked is for portrait terminals: the message on top, the file picker and the
+	// settings side by side below it and the render at the bottom.
+	layoutStacked
+	// layoutTabbed is for terminals too narrow or short for either: a tab bar and the message
+	// above whichever pane has the focus.
+	layoutTabbed
+)
+
+const (
+	// narrowWidth is the terminal width below which the panes become tabs.
+	narrowWidth = 60
+	// minFilePickerRows is how many files stay listed before the settings panel starts scrolling.
+	minFilePickerRows = 5
+	minSettingsRows   = 3
+	// settingsChrome is the border, padding, title and confirm button around the settings list.
+	settingsChrome = 8
+)
+
+type pane int
+
+const (
+	paneMessage pane = iota
+	panePicker
+	paneSettings
+	paneRender
+)
+
+// paneRect is the box of a pane on screen, borders included.
+type paneRect struct {
+	x, y, w, h int
+}
+
+func (r paneRect) contains(x, y int) bool {
+	return x >= r.x && x < r.x+r.w && y >= r.y && y < r.y+r.h
+}
+
+// layout is where every pane goes for one terminal size.
+type layout struct {
+	mode       layoutMode
+	fullscreen bool
+
+	// tabs is the tab bar of layoutTabbed, where the file picker, settings and render share
+	// one rect.
+	tabs                              paneRect
+	message, picker, settings, render paneRect
+}
+
+type layoutInput struct {
+	width, height int
+	menu          int
+	fullscreen    bool
+	messageRows   int
+	settingsRows  int
+	recentRows    int
+}
+
+// computeLayout picks the layout mode for the terminal size and places the panes. No rect is
+// ever smaller than its borders, even on a terminal too small to show anything useful.
+func computeLayout(in layoutInput) layout {
+	w, h := max(in.width, 2), max(in.height, 2)
+	messageH := in.messageRows + 2
+	minSettingsH := minSettingsRows + settingsChrome
+	// The recent files are left out of the choice of mode, they take from the file list first.
+	minPickerH := minFilePickerRows + 2
+
+	var l layout
+	switch {
+	case w >= narrowWidth && w >= 2*h && h >= messageH+minSettingsH+minPickerH:
+		l = wideLayout(in, w, h, messageH, minSettingsH, minPickerH+in.recentRows)
+	case w >= narrowWidth && (h-messageH)/2 >= max(minSettingsH, minPickerH):
+		l = stackedLayout(in, w, h, messageH, minSettingsH)
+	default:
+		l = tabbedLayout(w, h, messageH)
+	}
+
+	if in.fullscreen && in.menu == renderViewText {
+		l.fullscreen = true
+		l.render = paneRect{0, 0, w, h}
+	}
+	return l
+}
+
+func wideLayout(in layoutInput, w, h, messageH, minSettingsH, minPickerH int) layout {
+	leftW := w/7*2 + 2
+	below := h - messageH
+	settingsH := max(minSettingsH, min(in.settingsRows+settingsChrome, below-minPickerH))
+	return layout{
+		mode:     layoutWide,
+		message:  paneRect{0, 0, leftW, messageH},
+		picker:   paneRect{0, messageH, leftW, below - settingsH},
+		settings: paneRect{0, h - settingsH, leftW, settingsH},
+		render:   paneRect{leftW, 0, w - leftW, h},
+	}
+}
+
+func stackedLayout(in layoutInput, w, h, messageH, minSettingsH int) layout {
+	below := h - messageH
+	topH := max(minSettingsH, min(in.settingsRows+settingsChrome, below/2))
+	pickerW := w / 2
+	return layout{
+		mode:     layoutStacked,
+		message:  paneRect{0, 0, w, messageH},
+		picker:   paneRect{0, messageH, pickerW, topH},
+		settings: paneRect{pickerW, messageH, w - pickerW, topH},
+		render:   paneRect{0, messageH + topH, w, below - topH},
+	}
+}
+
+func tabbedLayout(w, h, messageH int) layout {
+	top := 1 + messageH
+	body := paneRect{0, top, w, max(3, h-top)}
+	return layout{
+		mode:     layoutTabbed,
+		tabs:     paneRect{0, 0, w, 1},
+		message:  paneRect{0, 1, w, messageH},
+		picker:   body,
+		settings: body,
+		render:   body,
+	}
+}
+
+func (l layout) rect(p pane) paneRect {
+	switch p {
+	case paneMessage:
+		return l.message
+	case panePicker:
+		return l.picker
+	case paneSettings:
+		return l.settings
+	}
+	return l.render
+}
+
+// shows reports whether pane p is on screen while menu has the focus.
+func (l layout) shows(p pane, menu int) bool {
+	if l.fullscreen {
+		return p == paneRender
+	}
+	if l.mode != layoutTabbed {
+		return true
+	}
+	switch p {
+	case panePicker:
+		return menu == filePickerMenu
+	case paneSettings:
+		return menu == renderOptionsMenu
+	case paneRender:
+		return menu == renderViewText
+	}
+	return true
+}
+
+// paneTab is one tab of the tab bar.
+type paneTab struct {
+	menu  int
+	label string
+}
+
+var paneTabs = []paneTab{
+	{filePickerMenu, "Files"},
+	{renderOptionsMenu, "Options"},
+	{renderViewText, "Render"},
+}
+
+const tabSeparator = "│"
+
+// tabsView is the tab bar of layoutTabbed with the focused pane highlighted.
+func (m *MezzotoneModel) tabsView(width, menu int) string {
+	faint := m.theme.FaintStyle()
+	active := m.theme.SelectedStyle().Bold(true)
+
+	tabs := make([]string, len(paneTabs))
+	for i, t := range paneTabs {
+		style := faint
+		if t.menu == menu {
+			style = active
+		}
+		tabs[i] = style.Render(" " + t.label + " ")
+	}
+	return termtext.TruncateLinesANSI(strings.Join(tabs, faint.Render(tabSeparator)), width)
+}
+
+// tabAt is the menu of the tab at column x of the tab bar.
+func tabAt(x int) (int, bool) {
+	left := 0
+	for _, t := range paneTabs {
+		right := left + lipgloss.Width(t.label) + 2
+		if x >= left && x < right {
+			return t.menu, true
+		}
+		left = right + lipgloss.Width(tabSeparator)
+	}
+	return 0, false
+}
+
+// boxView draws content in a bordered box exactly the size of r, cutting off what does not
+// fit.
+func (m *MezzotoneModel) boxView(r paneRect, content string) string {
+	w, h := max(0, r.w-2), max(0, r.h-2)
+	lines := strings.Split(content, "\n")
+	lines = lines[:min(len(lines), h)]
+	return m.theme.BoxStyle().
+		Width(w).
+		Height(h).
+		Render(termtext.TruncateLinesANSI(strings.Join(lines, "\n"), w))
+}
+
+// applyLayout places the panes for the terminal size and the focused menu and sizes every
+// component to its pane. The preview is only rendered again when the render pane changed
+// size.
+func (m *MezzotoneModel) applyLayout() {
+	if m.width == 0 || m.height == 0 {
+		return
+	}
+	l := computeLayout(layoutInput{
+		width:        m.width,
+		height:       m.height,
+		menu:         m.currentActiveMenu,
+		fullscreen:   m.fullscreen,
+		messageRows:  m.messageViewPort.Height,
+		settingsRows: m.renderSettings.ContentRows(),
+		recentRows:   m.recentRows(),
+	})
+	m.layout = l
+
+	m.style.leftColumnWidth = max(1, l.message.w-2)
+	m.messageViewPort.Width = m.style.leftColumnWidth
+
+	m.renderSettings.SetWidth(max(1, l.settings.w-2))
+	m.renderSettings.SetHeight(max(1, l.settings.h-settingsChrome))
+	// The file picker pads its list with one more line than its height.
+	m.filePicker.SetHeight(max(1, l.picker.h-2-m.recentRows()-1))
+
+	renderW, renderH := max(1, l.render.w-2), max(1, l.render.h-2)
+	resized := renderW != m.style.renderWidth || renderH != m.style.renderHeight
+	m.style.renderWidth, m.style.renderHeight = renderW, renderH
+	m.exportDialog.SetWidth(min(60, max(20, renderW-4)))
+	m.modal.SetWidth(min(60, max(20, renderW-4)))
+	if resized {
+		m.renderPreview()
+	}
+}
@@ -1,53 +1,91 @@
 package app
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/clipboard"
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/config"
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/export"
 	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/termgfx"
 	"codeberg.org/JoaoGarcia/Mezzotone/internal/termtext"
 	"codeberg.org/JoaoGarcia/Mezzotone/internal/ui"
-	"github.com/charmbracelet/bubbles/filepicker"
+	"codeberg.org/JoaoGarcia/Mezzotone/pkg/ascii"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-// TODO REORDER Layout IF TERMINAL width < height
 // FIXME for some fontsize image gets cut on right side
-// TODO image preview on selected file if applicable
 
 type MezzotoneModel struct {
-	filePicker   filepicker.Model
+	filePicker   filePicker
 	selectedFile string
 
 	renderView      viewport.Model
-	leftColumn      viewport.Model
 	renderSettings  ui.SettingsPanel
 	messageViewPort viewport.Model
+	exportDialog    ui.ExportDialog
+	modal           ui.Modal
 
 	style styleVariables
 
 	currentActiveMenu int
 	helpVisible       bool
 	helpPreviousMenu  int
+	renderResult      *services.RenderResult
 	renderContent     string
+	// renderedValues are the settings the current render was made with.
+	renderedValues map[string]string
+
+	playback    *playback
+	playbackSeq int
+	// exporting is the animated export running in the background, if any.
+	exporting *animationExport
+
+	graphics termgfx.Capabilities
+	// clipboard is where the render is copied to, OSC 52 going through the program's output.
+	clipboard clipboard.Environment
+	preview   previewState
+	split     splitState
+	compare   compareState
+	history   optionHistory
+	nav       renderNav
+	pending   pendingAction
+
+	keys    KeyMap
+	theme   ui.Theme
+	cfg     config.Config
+	cfgPath string
+	state   config.State
+	recent  recentList
 
 	width  int
 	height int
+	layout layout
+	// fullscreen gives the whole terminal to the render view while it has the focus.
+	fullscreen bool
 
 	err error
 }
 
 type styleVariables struct {
-	windowMargin    int
+	// leftColumnWidth is the content width of the message box.
 	leftColumnWidth int
+	// renderWidth and renderHeight are the size of the whole render pane, which the split
+	// view divides and the rulers take from.
+	renderWidth, renderHeight int
 }
 
-var renderSettingsItemsSize int
-
 const (
 	filePickerMenu = iota
 	renderOptionsMenu
@@ -55,41 +93,18 @@ const (
 )
 
 func NewMezzotoneModel() *MezzotoneModel {
-	windowStyles := styleVariables{
-		windowMargin: 2,
-	}
-
-	runeMode := []string{"ASCII", "UNICODE", "DOTS", "RECTANGLES", "BARS", "LOADING"}
-	renderSettingsItems := []ui.SettingItem{
-		{Label: "Text Size", Key: "textSize", Type: ui.TypeInt, Value: "10"},
-		{Label: "Font Aspect", Key: "fontAspect", Type: ui.TypeFloat, Value: "2.3"},
-		{Label: "Directional Render", Key: "directionalRender", Type: ui.TypeBool, Value: "FALSE"},
-		{Label: "Edge Threshold", Key: "edgeThreshold", Type: ui.TypeFloat, Value: "0.6"},
-		{Label: "Reverse Chars", Key: "reverseChars", Type: ui.TypeBool, Value: "TRUE"},
-		{Label: "High Contrast", Key: "highContrast", Type: ui.TypeBool, Value: "TRUE"},
-		{Label: "Rune Mode", Key: "runeMode", Type: ui.TypeEnum, Value: "ASCII", Enum: runeMode},
-	}
-	renderSettingsItemsSize = len(renderSettingsItems)
+	renderSettingsItems := renderSettingsItems(config.DefaultSettings())
 	renderSettingsModel := ui.NewSettingsPanel("Render Options", renderSettingsItems)
 	renderSettingsModel.ClearActive()
 
-	fp := filepicker.New()
-	fp.AllowedTypes = []string{".png", ".jpg", ".jpeg", ".bmp", ".webp", ".tiff", ".gif"}
+	fp := newFilePicker()
+	fp.AllowedTypes = append([]string{".png", ".jpg", ".jpeg", ".bmp", ".webp", ".tiff", ".gif"}, services.VideoExtensions...)
 	fp.CurrentDirectory, _ = os.UserHomeDir()
 	fp.ShowPermissions = false
 	fp.ShowSize = true
-	fp.KeyMap = filepicker.KeyMap{
-		Down:     key.NewBinding(key.WithKeys("j", "down"), key.WithHelp("j", "down")),
-		Up:       key.NewBinding(key.WithKeys("k", "up"), key.WithHelp("k", "up")),
-		GoToTop:  key.NewBinding(key.WithKeys("K", "pgup"), key.WithHelp("pgup", "page up")),
-		GoToLast: key.NewBinding(key.WithKeys("J", "pgdown"), key.WithHelp("pgdown", "page down")),
-		Back:     key.NewBinding(key.WithKeys("left", "backspace"), key.WithHelp("h", "back")),
-		Open:     key.NewBinding(key.WithKeys("right", "enter"), key.WithHelp("l", "open")),
-		Select:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select")),
-	}
 
 	renderView := viewport.New(0, 0)
-	leftColumn := viewport.New(0, 0)
+	renderView.SetHorizontalStep(mouseHorizontalStep)
 
 	messageViewPort := viewport.New(0, 3)
 
@@ -97,22 +112,46 @@ func NewMezzotoneModel() *MezzotoneModel {
 		filePicker:        fp,
 		renderView:        renderView,
 		messageViewPort:   messageViewPort,
-		style:             windowStyles,
-		leftColumn:        leftColumn,
 		renderSettings:    renderSettingsModel,
+		exportDialog:      ui.NewExportDialog(exportFormatsFor("")),
+		modal:             ui.NewModal(),
 		currentActiveMenu: filePickerMenu,
 		helpPreviousMenu:  filePickerMenu,
+		graphics:          termgfx.DefaultCapabilities(),
+		clipboard:         clipboard.DefaultEnvironment(nil),
+		split:             splitState{view: renderView},
+		cfg:               config.Default(),
 	}
+	model.SetKeyMap(DefaultKeyMap())
+	model.SetTheme(ui.DefaultTheme())
 	model.updateMessageViewPortContent("Select image gif or video to convert:", false)
 
 	return model
 }
 
+// SetClipboard sets where the render is copied to, see clipboard.DefaultEnvironment.
+func (m *MezzotoneModel) SetClipboard(env clipboard.Environment) {
+	m.clipboard = env
+}
+
+// SetGraphics selects how the file picker previews images, see termgfx.Detect.
+func (m *MezzotoneModel) SetGraphics(caps termgfx.Capabilities) {
+	m.graphics = caps
+}
+
 func (m *MezzotoneModel) Init() tea.Cmd {
 	return m.filePicker.Init()
 }
 
 func (m *MezzotoneModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	m.applyLayout()
+	m.layoutRenderPanes()
+	m.placePreview()
+	return model, cmd
+}
+
+func (m *MezzotoneModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var (
 		cmd  tea.Cmd
 		cmds []tea.Cmd
@@ -121,218 +160,622 @@ func (m *MezzotoneModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width, m.height = msg.Width, msg.Height
+		m.applyLayout()
+
+		m.updateMessageViewPortContent("Select image gif or video to convert:", false)
+
+		return m, nil
+
+	case playbackFrameMsg:
+		if m.playback == nil || msg.id != m.playback.id {
+			return m, nil
+		}
+		m.playback.frames++
+		m.playback.dropped += msg.dropped
+		m.renderResult = msg.result
+		m.renderContent = msg.result.String()
+		if !m.helpVisible {
+			m.renderView.SetContent(m.displayedRender())
+		}
+		if m.split.mode == splitSource {
+			m.updateSourceView(msg.frame)
+		}
+		return m, m.playback.nextFrameCmd()
+
+	case playbackDoneMsg:
+		if m.playback == nil || msg.id != m.playback.id {
+			return m, nil
+		}
+		if msg.err != nil && !errors.Is(msg.err, io.EOF) {
+			if m.showOptionErrors(msg.err) {
+				return m, nil
+			}
+			m.updateMessageViewPortContent("⚠ "+msg.err.Error(), true)
+		} else {
+			m.updateMessageViewPortContent(
+				fmt.Sprintf("Playback finished: %d frames, %d dropped", m.playback.frames, m.playback.dropped),
+				false,
+			)
+		}
+		m.stopPlayback()
+		return m, nil
+
+	case previewLoadedMsg:
+		m.handlePreviewLoaded(msg)
+		return m, nil
 
-		m.renderView.Height = m.height - m.style.windowMargin
-		m.renderView.Width = m.width / 7 * 5
+	case ui.ExportRequestMsg:
+		return m, m.handleExportRequest(msg)
 
-		m.style.leftColumnWidth = m.width / 7 * 2
+	case exportProgressMsg:
+		return m, m.handleExportProgress(msg)
 
-		m.renderSettings.SetWidth(m.style.leftColumnWidth)
-		m.renderSettings.SetHeight(renderSettingsItemsSize)
+	case exportDoneMsg:
+		m.handleExportDone(msg)
+		return m, nil
 
-		m.messageViewPort.Width = m.style.leftColumnWidth
+	case ui.PromptSubmitMsg:
+		m.handlePromptSubmit(msg)
+		return m, nil
 
-		computedFilePickerHeight := m.renderView.Height -
-			(renderSettingsItemsSize + 4) - //renderSettings header and end
-			(m.messageViewPort.Height + 2) - //message render view
-			(m.style.windowMargin + 3) //inputFile Title
+	case ui.ModalResultMsg:
+		return m, m.handleModalResult(msg)
 
-		m.filePicker.SetHeight(computedFilePickerHeight)
+	case ui.SettingsConfirmMsg:
+		if m.currentActiveMenu == renderOptionsMenu && m.renderSettings.Confirm {
+			return m, m.renderConfirmedOptions()
+		}
+		return m, nil
 
-		m.updateMessageViewPortContent("Select image gif or video to convert:", false)
+	case tea.MouseMsg:
+		return m, m.handleMouse(msg)
 
+	case copyDoneMsg:
+		if msg.err != nil {
+			_ = services.Logger().Errorf("copy to clipboard failed: %v", msg.err)
+			m.updateMessageViewPortContent("⚠ Copy failed: "+msg.err.Error(), true)
+			return m, nil
+		}
+		m.updateMessageViewPortContent(fmt.Sprintf("Copied %s to clipboard via %s", msg.kind, msg.method), false)
 		return m, nil
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "h":
-			if m.currentActiveMenu == renderOptionsMenu && m.renderSettings.Editing {
-				break
+		// The modal keeps the keyboard, even over the export dialog.
+		if m.modal.Visible {
+			if key.Matches(msg, m.keys.Quit) {
+				m.stopPlayback()
+				return m, tea.Quit
 			}
+			m.modal, cmd = m.modal.Update(msg)
+			return m, cmd
+		}
+		if m.exportDialog.Visible {
+			if key.Matches(msg, m.keys.Quit) {
+				m.stopPlayback()
+				return m, tea.Quit
+			}
+			m.exportDialog, cmd = m.exportDialog.Update(msg)
+			return m, cmd
+		}
+
+		renderShown := m.currentActiveMenu == renderViewText && !m.helpVisible && m.renderResult != nil
+		optionsIdle := m.currentActiveMenu == renderOptionsMenu && !m.renderSettings.Editing
+		switch {
+		case key.Matches(msg, m.keys.Quit):
+			m.stopPlayback()
+			return m, tea.Quit
+
+		case key.Matches(msg, m.keys.Help) && !(m.currentActiveMenu == renderOptionsMenu && m.renderSettings.Editing):
 			if m.helpVisible {
 				m.helpVisible = false
 				m.currentActiveMenu = m.helpPreviousMenu
-				m.renderView.SetContent(m.renderContent)
+				m.renderView.SetContent(m.displayedRender())
 				return m, nil
 			}
 			m.helpVisible = true
 			m.helpPreviousMenu = m.currentActiveMenu
 			m.currentActiveMenu = renderViewText
 			m.renderView.GotoTop()
-			m.renderView.SetContent(buildRenderHelpText())
-			return m, nil
-		case "ctrl+c":
-			return m, tea.Quit
+			m.renderView.SetContent(buildRenderHelpText(m.keys))
+			if m.helpPreviousMenu != filePickerMenu {
+				return m, nil
+			}
+			return m, m.clearSixelPreview()
 
-		case "esc":
+		case key.Matches(msg, m.keys.Back):
 			if m.helpVisible {
 				m.helpVisible = false
 				m.currentActiveMenu = m.helpPreviousMenu
-				m.renderView.SetContent(m.renderContent)
+				m.renderView.SetContent(m.displayedRender())
 				return m, nil
 			}
+			if m.currentActiveMenu == filePickerMenu && m.recent.focused {
+				m.recent.focused = false
+				return m, m.updatePreview()
+			}
 			if m.currentActiveMenu == filePickerMenu {
-				//TODO ask for confimation
-				return m, tea.Quit
+				m.modal.Confirm(quitModal, "Quit", "Leave Mezzotone?", "Quit")
+				return m, m.clearSixelPreview()
 			}
 			if m.currentActiveMenu == renderOptionsMenu {
-				if !m.renderSettings.Editing {
-					m.decrementCurrentActiveMenu()
-					m.renderSettings.ClearActive()
+				if m.renderSettings.Editing {
+					// The panel cancels the edit.
+					break
 				}
+				m.decrementCurrentActiveMenu()
+				m.renderSettings.ClearActive()
 				return m, cmd
 			}
 			if m.currentActiveMenu == renderViewText {
+				m.stopPlayback()
 				m.decrementCurrentActiveMenu()
 				return m, cmd
 			}
 
-		case "enter":
-			if m.currentActiveMenu == renderOptionsMenu {
-				if !m.renderSettings.Editing && m.renderSettings.Confirm {
-					m.incrementCurrentActiveMenu()
-
-					normalizedOptions := normalizeRenderOptionsForService(m.renderSettings.Items)
-					runeArray, err := services.ConvertImageToString(m.selectedFile, normalizedOptions)
-					if err != nil {
-						m.updateMessageViewPortContent("⚠ "+err.Error(), true)
-					}
-					m.renderContent = services.ImageRuneArrayIntoString(runeArray)
-					_ = services.Logger().Info(fmt.Sprintf("%s", m.renderContent))
-					if !m.helpVisible {
-						m.renderView.SetContent(m.renderContent)
-					}
-					return m, cmd
-				}
-			}
-		case "left":
-			if m.currentActiveMenu == renderViewText {
-				m.renderView.ScrollLeft(1)
-				return m, cmd
-			}
-		case "right":
-			if m.currentActiveMenu == renderViewText {
-				m.renderView.ScrollRight(1)
-				return m, cmd
-			}
-		case "up":
-			if m.currentActiveMenu == renderViewText {
-				m.renderView.ScrollUp(1)
-				return m, cmd
-			}
-		case "down":
-			if m.currentActiveMenu == renderViewText {
-				m.renderView.ScrollDown(1)
-				return m, cmd
+		case optionsIdle && m.renderSettings.Confirm && key.Matches(msg, m.keys.OptionsEdit):
+			return m, m.renderConfirmedOptions()
+		case optionsIdle && key.Matches(msg, m.keys.SavePreset):
+			m.openSavePresetPrompt()
+			return m, nil
+		case optionsIdle && key.Matches(msg, m.keys.OptionsTop):
+			m.renderSettings.SetActive(0)
+			return m, cmd
+		case optionsIdle && key.Matches(msg, m.keys.OptionsBottom):
+			m.renderSettings.SetActive(len(m.renderSettings.Items))
+			return m, cmd
+
+		case (optionsIdle || (m.currentActiveMenu == renderViewText && !m.helpVisible)) && key.Matches(msg, m.keys.Undo, m.keys.Redo):
+			if key.Matches(msg, m.keys.Undo) {
+				m.undoOptions()
+			} else {
+				m.redoOptions()
 			}
-		case "pgdown":
-			if m.currentActiveMenu == renderOptionsMenu {
-				m.renderSettings.SetActive(renderSettingsItemsSize)
-				return m, cmd
+			return m, nil
+
+		case renderShown && key.Matches(msg, m.keys.Copy, m.keys.CopyANSI):
+			return m, copyRenderCmd(m.renderResult, key.Matches(msg, m.keys.CopyANSI), m.clipboard)
+		case renderShown && key.Matches(msg, m.keys.Split):
+			m.toggleSplitView()
+			return m, nil
+		case renderShown && key.Matches(msg, m.keys.Pin):
+			m.togglePin()
+			return m, nil
+		case renderShown && key.Matches(msg, m.keys.Compare):
+			m.toggleCompareSplit()
+			return m, nil
+		case renderShown && key.Matches(msg, m.keys.Flip):
+			m.flipCompare()
+			return m, nil
+		case renderShown && key.Matches(msg, m.keys.Export):
+			m.exportDialog.SetFormats(exportFormatsFor(m.selectedFile))
+			m.exportDialog.Open(m.defaultExportPath())
+			return m, nil
+
+		case m.currentActiveMenu == renderViewText && key.Matches(msg, m.keys.Fullscreen):
+			m.fullscreen = !m.fullscreen
+			return m, nil
+
+		case renderShown && key.Matches(msg, m.keys.Rulers):
+			m.nav.rulers = !m.nav.rulers
+			return m, nil
+		case renderShown && key.Matches(msg, m.keys.Jump):
+			m.openJumpPrompt()
+			return m, nil
+		case renderShown && key.Matches(msg, m.keys.Find):
+			m.openFindPrompt()
+			return m, nil
+		case renderShown && key.Matches(msg, m.keys.FindNext, m.keys.FindPrevious):
+			m.repeatFind(key.Matches(msg, m.keys.FindPrevious))
+			return m, nil
+		case renderShown && key.Matches(msg, m.keys.Top):
+			m.scrollRenderPanes(func(v *viewport.Model) { v.GotoTop() })
+			return m, nil
+		case renderShown && key.Matches(msg, m.keys.Bottom):
+			m.scrollRenderPanes(func(v *viewport.Model) { v.GotoBottom() })
+			return m, nil
+		case renderShown && key.Matches(msg, m.keys.FirstColumn):
+			m.setRenderX(0)
+			return m, nil
+		case renderShown && key.Matches(msg, m.keys.LastColumn):
+			m.setRenderX(math.MaxInt)
+			return m, nil
+		case renderShown && key.Matches(msg, m.keys.PageLeft, m.keys.PageRight):
+			step := m.renderView.Width
+			if key.Matches(msg, m.keys.PageLeft) {
+				step = -step
 			}
-		case "pgup":
-			if m.currentActiveMenu == renderOptionsMenu {
-				m.renderSettings.SetActive(renderSettingsItemsSize)
-				return m, cmd
+			m.scrollRenderX(step)
+			return m, nil
+		case renderShown && key.Matches(msg, m.keys.HalfPageLeft, m.keys.HalfPageRight):
+			step := max(1, m.renderView.Width/2)
+			if key.Matches(msg, m.keys.HalfPageLeft) {
+				step = -step
 			}
+			m.scrollRenderX(step)
+			return m, nil
+
+		case m.currentActiveMenu == renderViewText && key.Matches(msg, m.keys.ScrollLeft):
+			m.scrollRenderX(-1)
+			return m, cmd
+		case m.currentActiveMenu == renderViewText && key.Matches(msg, m.keys.ScrollRight):
+			m.scrollRenderX(1)
+			return m, cmd
+		case m.currentActiveMenu == renderViewText && key.Matches(msg, m.keys.ScrollUp):
+			m.scrollRenderPanes(func(v *viewport.Model) { v.ScrollUp(1) })
+			return m, cmd
+		case m.currentActiveMenu == renderViewText && key.Matches(msg, m.keys.ScrollDown):
+			m.scrollRenderPanes(func(v *viewport.Model) { v.ScrollDown(1) })
+			return m, cmd
 		}
 	}
 
 	if m.currentActiveMenu == filePickerMenu {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && key.Matches(keyMsg, m.keys.PickerRecent) {
+			m.toggleRecentFocus()
+			return m, m.updatePreview()
+		}
+		if _, ok := msg.(tea.KeyMsg); ok && m.recent.focused {
+			return m, m.updateRecent(msg)
+		}
 		m.filePicker, cmd = m.filePicker.Update(msg)
 		cmds = append(cmds, cmd)
 		if didSelect, path := m.filePicker.DidSelectFile(msg); didSelect {
-			m.selectedFile = path
-			_ = services.Logger().Info(fmt.Sprintf("Selected File: %s", m.selectedFile))
-
-			m.renderSettings.SetActive(0)
-			m.renderSettings.Confirm = false
-			m.incrementCurrentActiveMenu()
-			return m, cmd
+			return m, tea.Batch(cmd, m.pickFile(path, true))
 		}
-
 		if didSelect, path := m.filePicker.DidSelectDisabledFile(msg); didSelect {
-			m.updateMessageViewPortContent("⚠ Selected file not allowed", true)
-			m.selectedFile = ""
-			_ = services.Logger().Info(fmt.Sprintf("Tried Selecting File: %s", path))
-			return m, cmd
+			return m, tea.Batch(cmd, m.pickFile(path, false))
 		}
+		cmds = append(cmds, m.updatePreview())
+		return m, tea.Batch(cmds...)
 	}
 	if m.currentActiveMenu == renderOptionsMenu {
-		m.renderSettings, cmd = m.renderSettings.Update(msg)
-		if errMsg := m.renderSettings.ErrorMessage(); errMsg != "" {
-			m.updateMessageViewPortContent("⚠ "+errMsg, true)
-		} else {
-			m.updateMessageViewPortContent("Edit render options and confirm:", false)
-		}
-		return m, cmd
+		return m, m.updateRenderSettings(msg)
 	}
 	if m.currentActiveMenu == renderViewText {
 		m.renderView, cmd = m.renderView.Update(msg)
+		if m.splitActive() {
+			m.split.view, _ = m.split.view.Update(msg)
+			m.split.view.SetYOffset(m.renderView.YOffset)
+		}
 		return m, cmd
 	}
 
 	return m, cmd
 }
 
+// updateRenderSettings passes msg to the settings panel, keeping the preset selection, the
+// option history and the message in step with it.
+func (m *MezzotoneModel) updateRenderSettings(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	previousPreset := m.presetItem().Value
+	before := itemValues(m.renderSettings.Items)
+	m.renderSettings, cmd = m.renderSettings.Update(msg)
+	m.syncPresetSelection(previousPreset)
+	m.history.record(before, itemValues(m.renderSettings.Items))
+	if errMsg := m.renderSettings.ErrorMessage(); errMsg != "" {
+		m.updateMessageViewPortContent("⚠ "+errMsg, true)
+	} else {
+		m.updateMessageViewPortContent("Edit render options and confirm:", false)
+	}
+	return cmd
+}
+
+// paneViews renders the message box and the file picker box.
+func (m *MezzotoneModel) paneViews() (message, picker string) {
+	message = m.boxView(m.layout.message, m.messageViewPort.View())
+
+	innerW := max(0, m.layout.picker.w-2)
+	picker = m.boxView(m.layout.picker, m.recentView(innerW)+m.filePicker.View())
+	return message, picker
+}
+
+// renderPaneView renders the render pane box.
+func (m *MezzotoneModel) renderPaneView() string {
+	content := m.renderView.View()
+	if m.previewVisible() {
+		content = m.previewView()
+	} else if m.splitActive() {
+		content = m.splitView()
+	}
+	if m.exportDialog.Visible {
+		content = lipgloss.Place(
+			m.style.renderWidth, m.style.renderHeight,
+			lipgloss.Center, lipgloss.Center,
+			m.exportDialog.View(),
+		)
+	}
+	if m.modal.Visible {
+		content = lipgloss.Place(
+			m.style.renderWidth, m.style.renderHeight,
+			lipgloss.Center, lipgloss.Center,
+			m.modal.View(),
+		)
+	}
+	if !m.exportDialog.Visible && !m.modal.Visible {
+		content = m.decorateRender(content)
+	}
+	return m.boxView(m.layout.render, content)
+}
+
 func (m *MezzotoneModel) View() string {
-	innerW := m.style.leftColumnWidth - 2
+	l := m.layout
+	message, picker := m.paneViews()
+	render := m.renderPaneView()
+
+	var screen string
+	switch {
+	case l.fullscreen:
+		screen = render
+	case l.mode == layoutStacked:
+		options := lipgloss.JoinHorizontal(lipgloss.Top, picker, m.renderSettings.View())
+		screen = lipgloss.JoinVertical(lipgloss.Left, message, options, render)
+	case l.mode == layoutTabbed:
+		// Dialogs are drawn in the render pane, which takes the place of the focused one.
+		focused := render
+		switch {
+		case m.modal.Visible || m.exportDialog.Visible:
+		case m.currentActiveMenu == filePickerMenu:
+			focused = picker
+		case m.currentActiveMenu == renderOptionsMenu:
+			focused = m.renderSettings.View()
+		}
+		screen = lipgloss.JoinVertical(lipgloss.Left, m.tabsView(l.tabs.w, m.currentActiveMenu), message, focused)
+	default:
+		leftColumn := lipgloss.JoinVertical(lipgloss.Left, message, picker, m.renderSettings.View())
+		screen = lipgloss.JoinHorizontal(lipgloss.Top, leftColumn, render)
+	}
+	return screen + m.preview.graphics
+}
 
-	messageViewportRenderStyle := lipgloss.NewStyle().
-		BorderStyle(lipgloss.NormalBorder()).
-		Width(m.style.leftColumnWidth)
-	messageViewportRender := messageViewportRenderStyle.Render(m.messageViewPort.View())
+// clearSixelPreview repaints the whole screen when a Sixel preview is left behind, because
+// Sixel pixels live in the text layer and only disappear where cells are rewritten.
+func (m *MezzotoneModel) clearSixelPreview() tea.Cmd {
+	if m.graphics.Protocol != termgfx.ProtocolSixel {
+		return nil
+	}
+	return tea.ClearScreen
+}
 
-	filePickerStyle := lipgloss.NewStyle().
-		BorderStyle(lipgloss.NormalBorder()).
-		Width(m.style.leftColumnWidth)
-	fpView := termtext.TruncateLinesANSI(m.filePicker.View(), innerW)
-	filePickerRender := filePickerStyle.Render(fpView)
+// startPlayback opens the selected video and schedules its first frame.
+func (m *MezzotoneModel) startPlayback(options services.RenderOptions) tea.Cmd {
+	m.stopPlayback()
 
-	lefColumnRender := lipgloss.JoinVertical(lipgloss.Top, messageViewportRender, filePickerRender, m.renderSettings.View())
+	source, err := services.OpenVideo(m.selectedFile)
+	if err != nil {
+		m.updateMessageViewPortContent("⚠ "+err.Error(), true)
+		return nil
+	}
 
-	renderViewStyle := lipgloss.NewStyle().
-		BorderStyle(lipgloss.NormalBorder())
-	renderViewRender := renderViewStyle.Render(m.renderView.View())
+	m.playbackSeq++
+	m.playback = &playback{
+		id:        m.playbackSeq,
+		source:    source,
+		done:      make(chan struct{}),
+		options:   options,
+		startedAt: time.Now(),
+	}
+	m.updateMessageViewPortContent(fmt.Sprintf("Playing video. Press %s to stop.", m.keys.Back.Help().Key), false)
+	return m.playback.nextFrameCmd()
+}
+
+func (m *MezzotoneModel) stopPlayback() {
+	if m.playback == nil {
+		return
+	}
+	m.playback.stop()
+	m.playback = nil
+}
 
-	return lipgloss.JoinHorizontal(lipgloss.Left, lefColumnRender, renderViewRender)
+type copyDoneMsg struct {
+	kind   string
+	method clipboard.Method
+	err    error
 }
 
-func normalizeRenderOptionsForService(settingsValues []ui.SettingItem) services.RenderOptions {
+// copyRenderCmd copies the render as plain text, or with ANSI colors when colored is set.
+func copyRenderCmd(result *services.RenderResult, colored bool, env clipboard.Environment) tea.Cmd {
+	kind, text := "plain text", result.String()
+	if colored {
+		kind, text = "ANSI text", export.ANSIString(result)
+	}
+	return func() tea.Msg {
+		method, err := clipboard.Copy(text, env)
+		return copyDoneMsg{kind: kind, method: method, err: err}
+	}
+}
+
+// defaultExportPath suggests a file next to the source image; the dialog adds the extension.
+func (m *MezzotoneModel) defaultExportPath() string {
+	base := strings.TrimSuffix(filepath.Base(m.selectedFile), filepath.Ext(m.selectedFile))
+	return filepath.Join(filepath.Dir(m.selectedFile), base+"_mezzotone")
+}
+
+// exportFormatsFor offers the still formats for every source and the animated ones for GIFs.
+func exportFormatsFor(path string) []ui.ExportFormat {
+	formats := export.Formats()
+	if services.IsGIFFile(path) {
+		formats = append(formats, export.AnimatedFormats()...)
+	}
+	exportFormats := make([]ui.ExportFormat, 0, len(formats))
+	for _, format := range formats {
+		exportFormats = append(exportFormats, ui.ExportFormat{Name: string(format), Extension: format.Extension()})
+	}
+	return exportFormats
+}
+
+func (m *MezzotoneModel) handleExportRequest(msg ui.ExportRequestMsg) tea.Cmd {
+	format := export.Format(msg.Format)
+	if !format.IsAnimated() {
+		m.finishExport(msg, export.WriteFile(msg.Path, m.renderResult, format, msg.Overwrite))
+		return nil
+	}
+
+	if m.exporting != nil {
+		m.updateMessageViewPortContent("⚠ An export is still running", true)
+		return nil
+	}
+	// Asked up front so rendering every frame is not wasted. The write still refuses to replace
+	// a file that appears in the meantime.
+	if _, err := os.Stat(msg.Path); err == nil && !msg.Overwrite {
+		m.finishExport(msg, fmt.Errorf("%w: %s", export.ErrExists, msg.Path))
+		return nil
+	}
+	m.exporting = startAnimationExport(msg, m.selectedFile, m.renderResult.Options)
+	m.updateMessageViewPortContent(fmt.Sprintf("Exporting %s to %s…", msg.Format, msg.Path), false)
+	return m.exporting.nextEventCmd()
+}
+
+// finishExport reports the outcome of the export msg asked for.
+func (m *MezzotoneModel) finishExport(msg ui.ExportRequestMsg, err error) {
+	switch {
+	case errors.Is(err, export.ErrExists):
+		m.pending.export = msg
+		m.modal.Confirm(overwriteModal, "Overwrite", msg.Path+" already exists. Replace it?", "Overwrite")
+	case err != nil:
+		// The dialog stays open under the error, to try another path or format.
+		_ = services.Logger().Errorf("export failed: %v", err)
+		m.modal.Error("Export failed", err.Error(), fmt.Sprintf("Path: %s\nFormat: %s", msg.Path, msg.Format))
+	default:
+		m.exportDialog.Close()
+		m.updateMessageViewPortContent(fmt.Sprintf("Saved %s to %s", msg.Format, msg.Path), false)
+	}
+}
+
+// normalizeRenderOptionsForService parses the settings items into render options. Values that
+// do not parse and options that do not validate are all reported in one *ascii.ValidationError.
+func normalizeRenderOptionsForService(settingsValues []ui.SettingItem) (services.RenderOptions, error) {
 	var textSize int
 	var fontAspect, edgeThreshold float64
 	var directionalRender, reverseChars, highContrast bool
 	var runeMode string
 
+	var parseErrs ascii.ValidationError
+	check := func(key string, err error, message string) {
+		if err != nil {
+			parseErrs.Fields = append(parseErrs.Fields, ascii.FieldError{Field: key, Message: message})
+		}
+	}
 	for _, item := range settingsValues {
+		var err error
 		switch item.Key {
 		case "textSize":
-			textSize, _ = strconv.Atoi(item.Value)
+			textSize, err = strconv.Atoi(item.Value)
+			check(item.Key, err, "must be an integer")
 
 		case "fontAspect":
-			fontAspect, _ = strconv.ParseFloat(item.Value, 2)
+			fontAspect, err = strconv.ParseFloat(item.Value, 64)
+			check(item.Key, err, "must be a number")
 
 		case "edgeThreshold":
-			edgeThreshold, _ = strconv.ParseFloat(item.Value, 2)
+			edgeThreshold, err = strconv.ParseFloat(item.Value, 64)
+			check(item.Key, err, "must be a number")
 
 		case "directionalRender":
-			directionalRender, _ = strconv.ParseBool(item.Value)
+			directionalRender, err = strconv.ParseBool(item.Value)
+			check(item.Key, err, "must be TRUE/FALSE")
 
 		case "reverseChars":
-			reverseChars, _ = strconv.ParseBool(item.Value)
+			reverseChars, err = strconv.ParseBool(item.Value)
+			check(item.Key, err, "must be TRUE/FALSE")
 
 		case "highContrast":
-			highContrast, _ = strconv.ParseBool(item.Value)
+			highContrast, err = strconv.ParseBool(item.Value)
+			check(item.Key, err, "must be TRUE/FALSE")
 
 		case "runeMode":
 			runeMode = item.Value
 		}
 	}
-	options, err := services.NewRenderOptions(textSize, fontAspect, directionalRender, edgeThreshold, reverseChars, highContrast, runeMode)
+	if err := parseErrs.Err(); err != nil {
+		return services.RenderOptions{}, err
+	}
+	return services.NewRenderOptions(textSize, fontAspect, directionalRender, edgeThreshold, reverseChars, highContrast, runeMode)
+}
+
+// renderConfirmedOptions renders the selected file with the options of the settings panel and
+// moves on to the render view, or back to the invalid options.
+func (m *MezzotoneModel) renderConfirmedOptions() tea.Cmd {
+	normalizedOptions, err := normalizeRenderOptionsForService(m.renderSettings.Items)
 	if err != nil {
-		//TODO render Error and go back to renderOptionsMenu
+		if !m.showOptionErrors(err) {
+			m.updateMessageViewPortContent("⚠ "+err.Error(), true)
+		}
+		return nil
+	}
+	m.renderSettings.SetFieldErrors(nil)
+	m.incrementCurrentActiveMenu()
+
+	m.renderedValues = settingValues(m.renderSettings.Items)
+	m.compare.flipped = false
+	if services.IsVideoFile(m.selectedFile) {
+		return m.startPlayback(normalizedOptions)
+	}
+	values := itemValues(m.renderSettings.Items)
+	result := m.history.cachedRender(values)
+	if result != nil {
+		m.updateMessageViewPortContent("Rendered "+result.Summary()+" (cached)", false)
+	} else if result, err = services.RenderImage(m.selectedFile, normalizedOptions); err != nil {
+		if m.showOptionErrors(err) {
+			return nil
+		}
+		m.modal.Error("Render failed", err.Error(), fmt.Sprintf("File: %s\n%s", m.selectedFile, optionDetails(m.renderSettings.Items)))
+	} else {
+		m.history.storeRender(values, result)
+		m.updateMessageViewPortContent("Rendered "+result.Summary(), false)
+	}
+	m.renderResult = result
+	m.renderContent = result.String()
+	_ = services.Logger().Info(fmt.Sprintf("%s", m.renderContent))
+	if !m.helpVisible {
+		m.renderView.SetContent(m.displayedRender())
+	}
+	if m.split.mode == splitSource {
+		m.split.source = ""
+		if err == nil {
+			if err := m.showStillSource(); err != nil {
+				m.updateMessageViewPortContent("⚠ "+err.Error(), true)
+			}
+		}
+	}
+	return nil
+}
+
+// optionDetails lists the render options by label, one per line.
+func optionDetails(items []ui.SettingItem) string {
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Key != presetKey {
+			lines = append(lines, item.Label+": "+item.Value)
+		}
 	}
-	return options
+	return strings.Join(lines, "\n")
+}
+
+// showOptionErrors highlights the fields named by a *ascii.ValidationError in the settings
+// panel and moves back to it. It reports false for other errors.
+func (m *MezzotoneModel) showOptionErrors(err error) bool {
+	var validationErr *ascii.ValidationError
+	if !errors.As(err, &validationErr) {
+		return false
+	}
+	fieldErrors := make(map[string]string, len(validationErr.Fields))
+	for _, field := range validationErr.Fields {
+		fieldErrors[field.Field] = field.Message
+	}
+	m.renderSettings.SetFieldErrors(fieldErrors)
+
+	m.stopPlayback()
+	m.helpVisible = false
+	m.currentActiveMenu = renderOptionsMenu
+	m.renderSettings.Confirm = false
+	for i, item := range m.renderSettings.Items {
+		if _, ok := fieldErrors[item.Key]; ok {
+			m.renderSettings.SetActive(i)
+			break
+		}
+	}
+	m.updateMessageViewPortContent("⚠ "+m.renderSettings.ErrorMessage(), true)
+	return true
 }
 
 func (m *MezzotoneModel) incrementCurrentActiveMenu() {
@@ -347,13 +790,14 @@ func (m *MezzotoneModel) incrementCurrentActiveMenu() {
 		messageViewContent = "Edit render options and confirm:"
 		break
 	case renderViewText:
-		//messageViewContent = "Press C to copy to clipboard"
+		messageViewContent = fmt.Sprintf("Press %s to copy (%s with colors), %s to save",
+			m.keys.Copy.Help().Key, m.keys.CopyANSI.Help().Key, m.keys.Export.Help().Key)
 		break
 	}
 
 	m.messageViewPort.SetContent(
 		termtext.TruncateLinesANSI(
-			messageViewContent+lipgloss.NewStyle().Faint(true).Render("\nPress h to toggle Help. Press esc to Quit."),
+			messageViewContent+m.theme.FaintStyle().Render("\n"+m.keyHint()),
 			m.style.leftColumnWidth,
 		),
 	)
@@ -377,22 +821,25 @@ func (m *MezzotoneModel) decrementCurrentActiveMenu() {
 
 	m.messageViewPort.SetContent(
 		termtext.TruncateLinesANSI(
-			messageViewContent+lipgloss.NewStyle().Faint(true).Render("\nPress h to toggle Help. Press esc to Quit."),
+			messageViewContent+m.theme.FaintStyle().Render("\n"+m.keyHint()),
 			m.style.leftColumnWidth,
 		),
 	)
 }
 
+// keyHint is the reminder of the help and quit keys under every message.
+func (m *MezzotoneModel) keyHint() string {
+	return fmt.Sprintf("Press %s to toggle Help. Press %s to Quit.", m.keys.Help.Help().Key, m.keys.Back.Help().Key)
+}
+
 func (m *MezzotoneModel) updateMessageViewPortContent(messageViewContent string, isError bool) {
 	if isError {
-		messageViewContent = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("9")).
-			Render(messageViewContent)
+		messageViewContent = m.theme.ErrorStyle().Render(messageViewContent)
 	}
 
 	m.messageViewPort.SetContent(
 		termtext.TruncateLinesANSI(
-			messageViewContent+lipgloss.NewStyle().Faint(true).Render("\nPress h to toggle Help. Press esc to Quit."),
+			messageViewContent+m.theme.FaintStyle().Render("\n"+m.keyHint()),
 			m.style.leftColumnWidth,
 		),
 	)
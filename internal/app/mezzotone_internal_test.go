@@ -1,11 +1,27 @@
 package app
 
 import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/config"
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
 	"codeberg.org/JoaoGarcia/Mezzotone/internal/termtext"
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/ui"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
 )
 
 func TestUpdateMessageViewPortContent_TruncatesByLeftColumnWidth(t *testing.T) {
@@ -25,3 +41,1047 @@ func TestUpdateMessageViewPortContent_TruncatesByLeftColumnWidth(t *testing.T) {
 		t.Fatalf("expected viewport to contain truncated first line %q, got %q", expectedFirstLine, view)
 	}
 }
+
+func TestPlaybackDeliversFramesThenDone(t *testing.T) {
+	var buf bytes.Buffer
+	frame := image.NewGray(image.Rect(0, 0, 16, 16))
+	if err := services.EncodeY4M(&buf, []image.Image{frame, frame}, 100); err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	source, err := services.NewY4MReader(&buf)
+	if err != nil {
+		t.Fatalf("failed opening y4m: %v", err)
+	}
+	options, err := services.NewRenderOptions(8, 2.0, false, 0.6, false, false, "ASCII")
+	if err != nil {
+		t.Fatalf("failed creating options: %v", err)
+	}
+
+	model := &MezzotoneModel{
+		renderView:      viewport.New(20, 5),
+		messageViewPort: viewport.New(8, 3),
+		style:           styleVariables{leftColumnWidth: 8},
+		playback:        &playback{id: 1, source: source, done: make(chan struct{}), options: options, startedAt: time.Now()},
+	}
+
+	cmd := model.playback.nextFrameCmd()
+	for i := 0; i < 2; i++ {
+		msg, ok := cmd().(playbackFrameMsg)
+		if !ok {
+			t.Fatalf("expected frame message %d", i)
+		}
+		_, cmd = model.Update(msg)
+		if model.renderContent == "" {
+			t.Fatalf("expected frame %d to be rendered", i)
+		}
+	}
+
+	if _, ok := cmd().(playbackDoneMsg); !ok {
+		t.Fatalf("expected done message after last frame")
+	}
+}
+
+// blockingSource blocks in Next until it is closed, like ffmpeg's pipe while a frame is decoded.
+type blockingSource struct {
+	closed chan struct{}
+}
+
+func (s *blockingSource) Next() (services.Frame, error) {
+	<-s.closed
+	return services.Frame{}, os.ErrClosed
+}
+
+func (s *blockingSource) Close() error {
+	close(s.closed)
+	return nil
+}
+
+func TestStopPlaybackEndsBlockedFrameCommand(t *testing.T) {
+	source := &blockingSource{closed: make(chan struct{})}
+	model := &MezzotoneModel{
+		playback: &playback{id: 1, source: source, done: make(chan struct{}), startedAt: time.Now()},
+	}
+
+	msgs := make(chan tea.Msg)
+	cmd := model.playback.nextFrameCmd()
+	go func() { msgs <- cmd() }()
+
+	model.stopPlayback()
+	select {
+	case msg := <-msgs:
+		if msg != nil {
+			t.Fatalf("expected a stopped playback to send nothing, got %T", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the frame command to return once the playback stopped")
+	}
+}
+
+func TestFilePickerPreviewFollowsHighlightedFile(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.png", "b c.png"} {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("failed creating %s: %v", name, err)
+		}
+		if err := png.Encode(f, image.NewGray(image.Rect(0, 0, 40, 20))); err != nil {
+			t.Fatalf("failed encoding %s: %v", name, err)
+		}
+		_ = f.Close()
+	}
+
+	model := NewMezzotoneModel()
+	model.filePicker.CurrentDirectory = dir
+	model.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+	model.Update(model.filePicker.Init()())
+
+	if got := model.filePicker.highlighted(); got != filepath.Join(dir, "a.png") {
+		t.Fatalf("expected first file highlighted, got %q", got)
+	}
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if model.preview.path != filepath.Join(dir, "b c.png") || cmd == nil {
+		t.Fatalf("expected preview of the file with a space to start loading, got %q", model.preview.path)
+	}
+
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			if c == nil {
+				continue
+			}
+			if loaded, ok := c().(previewLoadedMsg); ok {
+				msg = loaded
+			}
+		}
+	}
+	model.Update(msg)
+
+	if model.preview.rendered.Rows == 0 {
+		t.Fatalf("expected the loaded thumbnail to be rendered")
+	}
+	if content := model.previewView(); !strings.Contains(content, "b c.png · 40x20") {
+		t.Fatalf("expected caption with name and size, got %q", content)
+	}
+}
+
+func TestFilePickerTracksCursorThroughScrollingAndDirectories(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatalf("failed creating sub: %v", err)
+	}
+	names := []string{"a → b.png", "c.png", "d.png", "e.png", "f.png", "g.png", ".hidden.png"}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatalf("failed creating %s: %v", name, err)
+		}
+	}
+
+	model := NewMezzotoneModel()
+	model.filePicker.CurrentDirectory = dir
+	model.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+	model.Update(model.filePicker.Init()())
+	model.filePicker.SetHeight(3)
+
+	press := func(k tea.KeyType, times int) {
+		for range times {
+			model.filePicker, _ = model.filePicker.Update(tea.KeyMsg{Type: k})
+		}
+	}
+
+	// sub comes first, then the files by name without the hidden one.
+	press(tea.KeyDown, 1)
+	if got := model.filePicker.highlighted(); got != filepath.Join(dir, "a → b.png") {
+		t.Fatalf("expected the file with an arrow in its name, got %q", got)
+	}
+	press(tea.KeyDown, 3)
+	if got := model.filePicker.highlighted(); got != filepath.Join(dir, "e.png") {
+		t.Fatalf("expected e.png after scrolling, got %q", got)
+	}
+	// The list scrolled by two, so its first row is c.png.
+	if entry, ok := model.filePicker.entryAt(0); !ok || model.filePicker.entries[entry].Name() != "c.png" {
+		t.Fatalf("expected c.png on the first row, got entry %d", entry)
+	}
+	view := strings.Split(ansi.Strip(model.filePicker.View()), "\n")
+	if !strings.Contains(view[0], "c.png") {
+		t.Fatalf("expected the picker to show c.png first too, got %q", view[0])
+	}
+
+	press(tea.KeyUp, 5)
+	press(tea.KeyEnter, 1)
+	if got := model.filePicker.highlighted(); got != "" || model.filePicker.dir != filepath.Join(dir, "sub") {
+		t.Fatalf("expected the empty sub directory, got %q in %q", got, model.filePicker.dir)
+	}
+	press(tea.KeyBackspace, 1)
+	if got := model.filePicker.highlighted(); got != filepath.Join(dir, "sub") {
+		t.Fatalf("expected the cursor back on sub, got %q", got)
+	}
+}
+
+func TestSplitViewShowsSourceAndScrollsInSync(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tall.png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed creating fixture: %v", err)
+	}
+	if err := png.Encode(f, image.NewGray(image.Rect(0, 0, 40, 400))); err != nil {
+		t.Fatalf("failed encoding fixture: %v", err)
+	}
+	_ = f.Close()
+
+	options, err := services.NewRenderOptions(2, 2.0, false, 0.6, false, false, "ASCII")
+	if err != nil {
+		t.Fatalf("failed creating options: %v", err)
+	}
+	result, err := services.RenderImage(path, options)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	model := NewMezzotoneModel()
+	model.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+	model.selectedFile = path
+	model.currentActiveMenu = renderViewText
+	model.renderResult = result
+	model.renderContent = result.String()
+	model.renderView.SetContent(model.renderContent)
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+	if !model.splitActive() {
+		t.Fatalf("expected split view to be active")
+	}
+	if model.renderView.Width != model.split.view.Width || model.renderView.Width*2 >= model.style.renderWidth {
+		t.Fatalf("expected two equal halves of %d, got %d and %d", model.style.renderWidth, model.split.view.Width, model.renderView.Width)
+	}
+	if view := model.View(); !strings.Contains(view, "▀") {
+		t.Fatalf("expected half-block source in the view")
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if model.renderView.YOffset != 2 || model.split.view.YOffset != 2 {
+		t.Fatalf("expected both panes scrolled to 2, got %d and %d", model.renderView.YOffset, model.split.view.YOffset)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+	if model.splitActive() || model.renderView.Width != model.style.renderWidth {
+		t.Fatalf("expected toggling again to give the render the whole pane")
+	}
+}
+
+func TestPinnedRenderCanBeFlippedAndShownSideBySide(t *testing.T) {
+	model := NewMezzotoneModel()
+	model.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+	model.currentActiveMenu = renderViewText
+
+	showRender := func(content string, values map[string]string) {
+		model.renderResult = &services.RenderResult{Grid: [][]rune{[]rune(content)}, Cols: len(content), Rows: 1}
+		model.renderContent = content
+		model.renderedValues = values
+		model.renderView.SetContent(model.displayedRender())
+	}
+	key := func(k string) { model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}) }
+
+	showRender("AAAA", map[string]string{"runeMode": "ASCII"})
+	key("p")
+	model.renderSettings.Items[len(model.renderSettings.Items)-1].Value = "DOTS"
+	showRender("BBBB", map[string]string{"runeMode": "DOTS"})
+
+	if !strings.Contains(model.renderSettings.View(), "(A: ASCII)") {
+		t.Fatalf("expected the changed rune mode to be highlighted")
+	}
+
+	key("f")
+	if !strings.Contains(model.renderView.View(), "AAAA") {
+		t.Fatalf("expected flip to show A")
+	}
+	key("f")
+	if !strings.Contains(model.renderView.View(), "BBBB") {
+		t.Fatalf("expected flipping back to show B")
+	}
+
+	key("b")
+	if view := model.View(); !model.splitActive() || !strings.Contains(view, "AAAA") || !strings.Contains(view, "BBBB") {
+		t.Fatalf("expected A and B side by side")
+	}
+
+	key("p")
+	if model.splitActive() || model.compare.pinned != nil {
+		t.Fatalf("expected unpinning to close the comparison")
+	}
+}
+
+func TestPresetSelectorAppliesAndSavesPresets(t *testing.T) {
+	cfg := config.Default()
+	dense := cfg.Defaults
+	dense.TextSize, dense.RuneMode = 4, "UNICODE"
+	cfg.SetPreset("Dense", dense)
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	model := NewMezzotoneModel()
+	model.SetConfig(cfg, path)
+	model.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+	model.currentActiveMenu = renderOptionsMenu
+	model.renderSettings.SetActive(0)
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if got := renderSettingsFromItems(model.renderSettings.Items); got != dense || model.presetItem().Value != "Dense" {
+		t.Fatalf("expected the Dense preset to be loaded, got %+v (%s)", got, model.presetItem().Value)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if model.presetItem().Value != "Dense" {
+		t.Fatalf("moving the cursor should keep the preset selected")
+	}
+	model.renderSettings.Items[1].Value = "6"
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if model.presetItem().Value != customPreset {
+		t.Fatalf("expected a changed option to switch to %s, got %s", customPreset, model.presetItem().Value)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if !model.modal.Visible {
+		t.Fatalf("expected ctrl+s to ask for a preset name")
+	}
+	model.Update(ui.PromptSubmitMsg{ID: savePresetPrompt, Value: "Six"})
+	if model.modal.Visible || model.presetItem().Value != "Six" {
+		t.Fatalf("expected the new preset to be selected, got %s", model.presetItem().Value)
+	}
+
+	saved, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("load saved config: %v", err)
+	}
+	if six, err := saved.Preset("Six"); err != nil || six.TextSize != 6 || six.RuneMode != "UNICODE" {
+		t.Fatalf("unexpected saved preset %+v (%v)", six, err)
+	}
+}
+
+func TestSessionStateRestoresDirectoryRecentFilesAndOptions(t *testing.T) {
+	dir := t.TempDir()
+	imagePath := filepath.Join(dir, "cat.png")
+	if err := os.WriteFile(imagePath, []byte("not decoded here"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	last := config.DefaultSettings()
+	last.TextSize = 7
+
+	model := NewMezzotoneModel()
+	model.SetConfig(config.Default(), "")
+	model.SetState(config.State{
+		LastDirectory: dir,
+		RecentFiles:   []string{filepath.Join(dir, "gone.png"), imagePath},
+		LastSettings:  &last,
+	})
+	model.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+
+	if model.filePicker.CurrentDirectory != dir {
+		t.Fatalf("expected the picker to start in %s, got %s", dir, model.filePicker.CurrentDirectory)
+	}
+	if len(model.state.RecentFiles) != 1 || !strings.Contains(model.View(), "cat.png") {
+		t.Fatalf("expected only the existing recent file to be listed, got %v", model.state.RecentFiles)
+	}
+	if got := renderSettingsFromItems(model.renderSettings.Items); got != last {
+		t.Fatalf("expected the last options to be restored, got %+v", got)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyTab})
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.selectedFile != imagePath || model.currentActiveMenu != renderOptionsMenu {
+		t.Fatalf("expected enter on a recent file to select it, got %q", model.selectedFile)
+	}
+
+	model.renderSettings.Items[1].Value = "9"
+	state := model.SessionState()
+	if state.LastDirectory != dir || state.LastSettings.TextSize != 9 || state.RecentFiles[0] != imagePath {
+		t.Fatalf("unexpected session state %+v", state)
+	}
+}
+
+func TestUndoRedoRestoresOptionsAndCachedRenders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cat.png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed creating fixture: %v", err)
+	}
+	if err := png.Encode(f, image.NewGray(image.Rect(0, 0, 40, 20))); err != nil {
+		t.Fatalf("failed encoding fixture: %v", err)
+	}
+	_ = f.Close()
+
+	model := NewMezzotoneModel()
+	model.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+	model.selectedFile = path
+	model.currentActiveMenu = renderOptionsMenu
+	items := len(model.renderSettings.Items)
+	key := func(msg tea.KeyMsg) { model.Update(msg) }
+	render := func() *services.RenderResult {
+		model.renderSettings.SetActive(items)
+		model.renderSettings.Confirm = true
+		key(tea.KeyMsg{Type: tea.KeyEnter})
+		result := model.renderResult
+		key(tea.KeyMsg{Type: tea.KeyEsc})
+		return result
+	}
+
+	first := render()
+	model.renderSettings.SetActive(items - 1)
+	key(tea.KeyMsg{Type: tea.KeyRight})
+	second := render()
+	if first == second || model.renderSettings.Items[items-1].Value != "UNICODE" {
+		t.Fatalf("expected a second render with the next rune mode")
+	}
+
+	key(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	if model.renderSettings.Items[items-1].Value != "ASCII" || model.renderResult != first {
+		t.Fatalf("expected undo to restore ASCII and its cached render")
+	}
+	if again := render(); again != first {
+		t.Fatalf("expected confirming the undone options to reuse the cached render")
+	}
+
+	key(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if model.renderSettings.Items[items-1].Value != "UNICODE" || model.renderResult != second {
+		t.Fatalf("expected redo to bring back UNICODE and its render")
+	}
+
+	key(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	model.renderSettings.SetActive(items - 1)
+	key(tea.KeyMsg{Type: tea.KeyLeft})
+	key(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if model.renderSettings.Items[items-1].Value != "LOADING" {
+		t.Fatalf("expected a new change to drop the redo entries, got %s", model.renderSettings.Items[items-1].Value)
+	}
+}
+
+func TestInvalidOptionsReturnToTheOptionsWithFieldsHighlighted(t *testing.T) {
+	model := NewMezzotoneModel()
+	model.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+	model.selectedFile = filepath.Join(t.TempDir(), "unused.png")
+	model.currentActiveMenu = renderOptionsMenu
+
+	index := func(key string) int {
+		for i, item := range model.renderSettings.Items {
+			if item.Key == key {
+				return i
+			}
+		}
+		t.Fatalf("no %s item", key)
+		return -1
+	}
+	model.renderSettings.Items[index("textSize")].Value = "0"
+	model.renderSettings.Items[index("edgeThreshold")].Value = "3"
+	model.renderSettings.SetActive(len(model.renderSettings.Items))
+	model.renderSettings.Confirm = true
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if model.currentActiveMenu != renderOptionsMenu || model.renderResult != nil {
+		t.Fatalf("expected to stay in the options without rendering")
+	}
+	if got := model.renderSettings.ErrorMessage(); got != "Text Size must be at least 1" {
+		t.Fatalf("expected the cursor on the first invalid field, got %q", got)
+	}
+	if view := model.renderSettings.View(); strings.Count(view, "⚠") != 2 {
+		t.Fatalf("expected both invalid fields to be marked, got %q", view)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if strings.Count(model.renderSettings.View(), "⚠") != 1 {
+		t.Fatalf("expected changing a field to clear its error")
+	}
+}
+
+func TestNormalizeRenderOptionsReportsParseErrors(t *testing.T) {
+	items := renderSettingsItems(config.DefaultSettings())
+	for i := range items {
+		if items[i].Key == "fontAspect" {
+			items[i].Value = "tall"
+		}
+	}
+	_, err := normalizeRenderOptionsForService(items)
+	if err == nil || !strings.Contains(err.Error(), "fontAspect must be a number") {
+		t.Fatalf("expected a fontAspect parse error, got %v", err)
+	}
+}
+
+func TestMouseSelectsFileConfirmsOptionsAndScrollsRender(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.png", "tall.png"} {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("failed creating %s: %v", name, err)
+		}
+		if err := png.Encode(f, image.NewGray(image.Rect(0, 0, 40, 2000))); err != nil {
+			t.Fatalf("failed encoding %s: %v", name, err)
+		}
+		_ = f.Close()
+	}
+
+	model := NewMezzotoneModel()
+	// The mouse does not depend on the keys the actions are bound to.
+	keys, err := NewKeyMap(map[string][]string{
+		"picker.up": {"w"}, "picker.down": {"s"}, "picker.open": {"o"}, "options.edit": {"e"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	model.SetKeyMap(keys)
+	model.filePicker.CurrentDirectory = dir
+	model.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+	model.Update(model.filePicker.Init()())
+
+	var run func(cmd tea.Cmd)
+	run = func(cmd tea.Cmd) {
+		if cmd == nil {
+			return
+		}
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			for _, c := range batch {
+				run(c)
+			}
+			return
+		}
+		_, next := model.Update(msg)
+		run(next)
+	}
+	click := func(text string) {
+		t.Helper()
+		for y, line := range strings.Split(ansi.Strip(model.View()), "\n") {
+			if x := strings.Index(line, text); x >= 0 && x < model.style.leftColumnWidth {
+				_, cmd := model.Update(tea.MouseMsg{X: 2, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+				run(cmd)
+				return
+			}
+		}
+		t.Fatalf("%q is not in the left column", text)
+	}
+
+	wheel := func(button tea.MouseButton) {
+		_, cmd := model.Update(tea.MouseMsg{X: 2, Y: model.layout.picker.y + 2, Action: tea.MouseActionPress, Button: button})
+		run(cmd)
+	}
+	wheel(tea.MouseButtonWheelDown)
+	if got := model.filePicker.highlighted(); got != filepath.Join(dir, "tall.png") {
+		t.Fatalf("expected the wheel to move down to tall.png, got %q", got)
+	}
+	wheel(tea.MouseButtonWheelUp)
+	if got := model.filePicker.highlighted(); got != filepath.Join(dir, "a.png") {
+		t.Fatalf("expected the wheel to move back up to a.png, got %q", got)
+	}
+
+	click("tall.png")
+	if got := model.filePicker.highlighted(); got != filepath.Join(dir, "tall.png") || model.currentActiveMenu != filePickerMenu {
+		t.Fatalf("expected the first click to highlight tall.png, got %q", got)
+	}
+	click("tall.png")
+	if model.selectedFile != filepath.Join(dir, "tall.png") || model.currentActiveMenu != renderOptionsMenu {
+		t.Fatalf("expected the second click to select tall.png, got %q", model.selectedFile)
+	}
+
+	reverse := model.renderSettings.Items[5].Value
+	click("Reverse Chars")
+	if got := model.renderSettings.Items[5].Value; strings.EqualFold(got, reverse) {
+		t.Fatalf("expected clicking a bool to toggle it, still %s", got)
+	}
+
+	click("Text Size")
+	click("Text Size")
+	if !model.renderSettings.Editing {
+		t.Fatalf("expected clicking the current number to edit it")
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	click("CONFIRM")
+	if model.currentActiveMenu != renderViewText || model.renderResult == nil {
+		t.Fatalf("expected clicking CONFIRM to render")
+	}
+
+	model.Update(tea.MouseMsg{X: model.style.leftColumnWidth + 10, Y: 5, Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown})
+	if model.renderView.YOffset == 0 {
+		t.Fatalf("expected the wheel to scroll the render")
+	}
+}
+
+func TestStatusMessagesNameTheBoundKeys(t *testing.T) {
+	keys, err := NewKeyMap(map[string][]string{"render.pin": {"P"}, "render.compare": {"B"}, "render.flip": {"F"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	model := NewMezzotoneModel()
+	model.SetKeyMap(keys)
+	model.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+	message := func() string { return ansi.Strip(model.messageViewPort.View()) }
+
+	model.flipCompare()
+	if !strings.Contains(message(), "Pin a render with P first") {
+		t.Fatalf("expected the rebound pin key, got %q", message())
+	}
+	model.renderResult = &services.RenderResult{Grid: [][]rune{[]rune("x")}, Cols: 1, Rows: 1}
+	model.togglePin()
+	if !strings.Contains(message(), "then B: side") {
+		t.Fatalf("expected the rebound compare key, got %q", message())
+	}
+}
+
+func TestDefaultKeyMapHasNoConflicts(t *testing.T) {
+	keys := DefaultKeyMap()
+	if err := keys.Conflicts(); err != nil {
+		t.Fatalf("default keys conflict: %v", err)
+	}
+}
+
+func TestNewKeyMapRebindsAndReportsConflicts(t *testing.T) {
+	keys, err := NewKeyMap(map[string][]string{"help": {"?"}, "picker.up": {"c"}})
+	if err != nil {
+		t.Fatalf("expected keys of different panes not to conflict: %v", err)
+	}
+	if !key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")}, keys.Help) ||
+		key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")}, keys.Help) {
+		t.Fatalf("expected help on ? only, got %v", keys.Help.Keys())
+	}
+	if text := buildRenderHelpText(keys); !strings.Contains(text, "?") {
+		t.Fatalf("expected the help text to show the new key")
+	}
+
+	_, err = NewKeyMap(map[string][]string{"render.copy": {"v"}, "nope": {"x"}, "quit": {"s"}})
+	for _, want := range []string{`unknown action "nope"`, "v is bound to both render.copy and render.split", "s is bound to both quit and render.export"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected error to mention %q, got %v", want, err)
+		}
+	}
+}
+
+func TestRebindHelpFreesTheKey(t *testing.T) {
+	keys, err := NewKeyMap(map[string][]string{"help": {"?"}, "options.decrease": {"left", "h"}})
+	if err != nil {
+		t.Fatalf("new key map: %v", err)
+	}
+	model := NewMezzotoneModel()
+	model.SetKeyMap(keys)
+	model.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+	model.selectedFile = "cat.png"
+	model.currentActiveMenu = renderOptionsMenu
+	last := len(model.renderSettings.Items) - 1
+	model.renderSettings.SetActive(last)
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	if model.helpVisible || model.renderSettings.Items[last].Value != "LOADING" {
+		t.Fatalf("expected h to step the rune mode back, got help=%v value=%s", model.helpVisible, model.renderSettings.Items[last].Value)
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")})
+	if !model.helpVisible {
+		t.Fatalf("expected ? to open the help")
+	}
+	if !strings.Contains(ansi.Strip(model.View()), "Press ? to toggle Help") {
+		t.Fatalf("expected the hint to name the new help key")
+	}
+}
+
+func TestComputeLayoutPicksModeAndKeepsPanesOnScreen(t *testing.T) {
+	cases := []struct {
+		width, height int
+		want          layoutMode
+	}{
+		{140, 40, layoutWide},
+		{80, 24, layoutWide},
+		{70, 60, layoutStacked},
+		{100, 60, layoutStacked},
+		{40, 30, layoutTabbed},
+		{120, 12, layoutTabbed},
+		{1, 1, layoutTabbed},
+		{0, 0, layoutTabbed},
+	}
+	for _, tc := range cases {
+		for _, menu := range []int{filePickerMenu, renderOptionsMenu, renderViewText} {
+			l := computeLayout(layoutInput{
+				width: tc.width, height: tc.height, menu: menu,
+				messageRows: 3, settingsRows: 11, recentRows: 5,
+			})
+			if l.mode != tc.want {
+				t.Fatalf("%dx%d: expected mode %d, got %d", tc.width, tc.height, tc.want, l.mode)
+			}
+			for _, p := range []pane{paneMessage, panePicker, paneSettings, paneRender} {
+				r := l.rect(p)
+				if r.w < 2 || r.h < 2 || r.x < 0 || r.y < 0 {
+					t.Fatalf("%dx%d: pane %d has rect %+v", tc.width, tc.height, p, r)
+				}
+				if l.mode != layoutTabbed && (r.x+r.w > tc.width || r.y+r.h > tc.height) {
+					t.Fatalf("%dx%d: pane %d rect %+v is off screen", tc.width, tc.height, p, r)
+				}
+			}
+		}
+	}
+
+	l := computeLayout(layoutInput{width: 140, height: 40, menu: renderViewText, fullscreen: true, messageRows: 3, settingsRows: 11})
+	if !l.fullscreen || l.render != (paneRect{0, 0, 140, 40}) || l.shows(panePicker, renderViewText) {
+		t.Fatalf("expected the render to fill the screen, got %+v", l)
+	}
+	l = computeLayout(layoutInput{width: 140, height: 40, menu: renderOptionsMenu, fullscreen: true, messageRows: 3, settingsRows: 11})
+	if l.fullscreen {
+		t.Fatal("expected full screen to only apply to the render view")
+	}
+}
+
+func TestNarrowTerminalShowsTabsAndFullscreenRender(t *testing.T) {
+	model := NewMezzotoneModel()
+	model.Update(tea.WindowSizeMsg{Width: 40, Height: 30})
+
+	view := ansi.Strip(model.View())
+	lines := strings.Split(view, "\n")
+	if !strings.Contains(lines[0], "Files") || !strings.Contains(lines[0], "Render") {
+		t.Fatalf("expected a tab bar, got %q", lines[0])
+	}
+	if strings.Contains(view, "RENDER OPTIONS") {
+		t.Fatal("expected the settings panel to be hidden behind its tab")
+	}
+	for _, line := range lines {
+		if ansi.StringWidth(line) > 40 {
+			t.Fatalf("line wider than the terminal: %q", line)
+		}
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+	if !model.layout.fullscreen || model.renderView.Height != 28 || model.style.renderWidth != 38 {
+		t.Fatalf("expected the render to fill the terminal, got %dx%d", model.style.renderWidth, model.renderView.Height)
+	}
+	if strings.Contains(ansi.Strip(model.View()), "Files │") {
+		t.Fatal("expected the tab bar to be hidden in full screen")
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if model.layout.fullscreen {
+		t.Fatal("expected full screen to end when leaving the render view")
+	}
+}
+
+func TestResolveThemePicksBuiltinAndUserThemes(t *testing.T) {
+	dark := func() bool { return true }
+	light := func() bool { return false }
+	builtin := func(name string) ui.Theme {
+		theme, ok := ui.BuiltinTheme(name)
+		if !ok {
+			t.Fatalf("missing built-in theme %q", name)
+		}
+		return theme
+	}
+
+	cfg := config.Default()
+	if got, err := ResolveTheme(cfg, light); err != nil || got != builtin(ui.ThemeLight) {
+		t.Fatalf("expected auto to pick the light theme on a light background, got %+v (%v)", got, err)
+	}
+	if got, err := ResolveTheme(cfg, dark); err != nil || got != builtin(ui.ThemeDark) {
+		t.Fatalf("expected auto to pick the dark theme on a dark background, got %+v (%v)", got, err)
+	}
+
+	cfg.Theme = "High-Contrast"
+	asked := false
+	if got, err := ResolveTheme(cfg, func() bool { asked = true; return true }); err != nil || got != builtin(ui.ThemeHighContrast) || asked {
+		t.Fatalf("expected the high contrast theme without a background query, got %+v (%v, asked %v)", got, err, asked)
+	}
+
+	cfg.Theme = "mine"
+	cfg.Themes = map[string]config.ThemeColors{"Mine": {Base: "light", Accent: "#f80", Border: "33"}}
+	got, err := ResolveTheme(cfg, dark)
+	want := builtin(ui.ThemeLight)
+	want.Accent, want.Border = "#ff8800", "33"
+	if err != nil || got != want {
+		t.Fatalf("expected %+v, got %+v (%v)", want, got, err)
+	}
+
+	cfg.Themes["Mine"] = config.ThemeColors{Faint: "300"}
+	if _, err := ResolveTheme(cfg, dark); err == nil || !strings.Contains(err.Error(), "faint") {
+		t.Fatalf("expected an out of range faint color to be rejected, got %v", err)
+	}
+	cfg.Theme = "solarized"
+	if _, err := ResolveTheme(cfg, dark); err == nil || !strings.Contains(err.Error(), "solarized") {
+		t.Fatalf("expected an unknown theme to be rejected, got %v", err)
+	}
+}
+
+func TestSetThemeOnlyColorsItsOwnModel(t *testing.T) {
+	contrast, _ := ui.BuiltinTheme(ui.ThemeHighContrast)
+	themed, plain := NewMezzotoneModel(), NewMezzotoneModel()
+	themed.SetTheme(contrast)
+
+	if themed.theme != contrast || themed.renderSettings.Theme != contrast || themed.exportDialog.Theme != contrast || themed.modal.Theme != contrast {
+		t.Fatalf("expected the theme to reach every component")
+	}
+	if plain.theme != ui.DefaultTheme() || plain.modal.Theme != ui.DefaultTheme() {
+		t.Fatalf("expected another model to keep the default theme")
+	}
+}
+
+func TestLightThemeColorsTheFilePicker(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	t.Cleanup(func() { lipgloss.SetColorProfile(termenv.Ascii) })
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.png"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pickerView := func(theme ui.Theme) string {
+		model := NewMezzotoneModel()
+		model.SetTheme(theme)
+		model.filePicker.CurrentDirectory = dir
+		model.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+		model.Update(model.filePicker.Init()())
+		return model.filePicker.View()
+	}
+
+	light, _ := ui.BuiltinTheme(ui.ThemeLight)
+	dark, lightView := pickerView(ui.DefaultTheme()), pickerView(light)
+	if dark == lightView {
+		t.Fatalf("expected the light theme to change the file picker")
+	}
+	// The bubbles default pink no longer shows up, the light accent and faint colors do.
+	if strings.Contains(lightView, "38;5;212") || !strings.Contains(lightView, "38;5;162") || !strings.Contains(lightView, "38;5;243") {
+		t.Fatalf("expected the picker in the light theme colors, got %q", lightView)
+	}
+}
+
+func TestRenderRulersStatusAndJump(t *testing.T) {
+	model := NewMezzotoneModel()
+	model.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+	model.currentActiveMenu = renderViewText
+
+	grid := make([][]rune, 120)
+	lines := make([]string, len(grid))
+	for i := range grid {
+		grid[i] = []rune(strings.Repeat("x", 300))
+		lines[i] = string(grid[i])
+	}
+	model.renderResult = &services.RenderResult{Grid: grid, Cols: 300, Rows: 120}
+	model.renderContent = strings.Join(lines, "\n")
+	model.renderView.SetContent(model.renderContent)
+	key := func(k string) { model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}) }
+
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if view := ansi.Strip(model.View()); !strings.Contains(view, "rows 2-") || !strings.Contains(view, "of 120") || !strings.Contains(view, "of 300") {
+		t.Fatalf("expected a status line with the offset and grid size, got %q", view)
+	}
+
+	key("r")
+	width := model.renderView.Width
+	if width != model.style.renderWidth-4 || model.renderView.Height != model.style.renderHeight-2 {
+		t.Fatalf("expected the rulers to take a 4 column gutter and a line, got %dx%d of %dx%d",
+			width, model.renderView.Height, model.style.renderWidth, model.style.renderHeight)
+	}
+	if view := ansi.Strip(model.View()); !strings.Contains(view, "  2 xxx") || !strings.Contains(view, "·    10") {
+		t.Fatalf("expected row numbers and a column ruler, got %q", view)
+	}
+
+	key("$")
+	if model.nav.x != 300-width {
+		t.Fatalf("expected the last column, got offset %d", model.nav.x)
+	}
+	key("[")
+	key("{")
+	if model.nav.x != 300-width-width-width/2 {
+		t.Fatalf("expected a page and a half to the left, got offset %d", model.nav.x)
+	}
+	key("0")
+	key("G")
+	if model.nav.x != 0 || !model.renderView.AtBottom() {
+		t.Fatalf("expected the first column of the last page, got %d/%d", model.nav.x, model.renderView.YOffset)
+	}
+
+	key(":")
+	if !model.modal.Visible {
+		t.Fatal("expected the jump prompt")
+	}
+	model.modal.Prompt(jumpPrompt, "Jump", "abc")
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model.Update(cmd())
+	if !model.modal.Visible {
+		t.Fatal("expected an invalid jump to keep the prompt open")
+	}
+	model.modal.Prompt(jumpPrompt, "Jump", "10,50")
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model.Update(cmd())
+	if model.modal.Visible || model.renderView.YOffset != 9 || model.nav.x != 49 {
+		t.Fatalf("expected row 10 and column 50 at the top left, got %d,%d", model.renderView.YOffset, model.nav.x)
+	}
+	if row, col, err := parseJump(",7"); err != nil || row != 0 || col != 7 {
+		t.Fatalf("expected only a column, got %d,%d (%v)", row, col, err)
+	}
+}
+
+func TestRenderFindJumpsBetweenMatches(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	t.Cleanup(func() { lipgloss.SetColorProfile(termenv.Ascii) })
+
+	model := NewMezzotoneModel()
+	model.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+	model.currentActiveMenu = renderViewText
+
+	grid := make([][]rune, 120)
+	lines := make([]string, len(grid))
+	for i := range grid {
+		grid[i] = []rune(strings.Repeat("x", 300))
+	}
+	copy(grid[5][10:], []rune("cat"))
+	copy(grid[100][250:], []rune("cat"))
+	for i := range grid {
+		lines[i] = string(grid[i])
+	}
+	model.renderResult = &services.RenderResult{Grid: grid, Cols: 300, Rows: 120}
+	model.renderContent = strings.Join(lines, "\n")
+	model.renderView.SetContent(model.renderContent)
+	key := func(k string) { model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}) }
+	submit := func(query string) {
+		model.modal.Prompt(findPrompt, "Find in render", query)
+		_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		model.Update(cmd())
+	}
+
+	key("n")
+	if !strings.Contains(ansi.Strip(model.View()), "Find a text with / first") {
+		t.Fatal("expected a hint to search first")
+	}
+	key("/")
+	if !model.modal.Visible {
+		t.Fatal("expected the find prompt")
+	}
+	submit("dog")
+	if !model.modal.Visible {
+		t.Fatal("expected a missing text to keep the prompt open")
+	}
+	submit("cat")
+	if model.modal.Visible || *model.nav.match != (renderMatch{row: 5, col: 10}) {
+		t.Fatalf("expected the first match, got %+v", model.nav.match)
+	}
+	if view := ansi.Strip(model.View()); !strings.Contains(view, "match 1 of 2") || !strings.Contains(view, "xcatx") {
+		t.Fatalf("expected the match in view and counted, got %q", view)
+	}
+	if line := model.highlightMatch(lines[5], 5, 0); line == lines[5] || ansi.Strip(line) != lines[5] {
+		t.Fatalf("expected the match to be highlighted, got %q", line)
+	}
+
+	// The second match is off screen on both axes.
+	key("n")
+	width, height := model.renderView.Width, model.renderView.Height
+	if model.renderView.YOffset > 100 || model.renderView.YOffset+height <= 100 || model.nav.x > 250 || model.nav.x+width < 253 {
+		t.Fatalf("expected the view to scroll to row 100, column 250, got %d,%d", model.renderView.YOffset, model.nav.x)
+	}
+	if !strings.Contains(ansi.Strip(model.View()), "match 2 of 2") {
+		t.Fatal("expected the second match")
+	}
+	key("n")
+	if *model.nav.match != (renderMatch{row: 5, col: 10}) || model.renderView.YOffset > 5 || model.nav.x > 10 {
+		t.Fatalf("expected the search to wrap to the first match, got %+v at %d,%d", model.nav.match, model.renderView.YOffset, model.nav.x)
+	}
+	key("N")
+	if *model.nav.match != (renderMatch{row: 100, col: 250}) {
+		t.Fatalf("expected the previous match to wrap to the last one, got %+v", model.nav.match)
+	}
+}
+
+func TestModalConfirmsOverwriteAndDiscardingOptions(t *testing.T) {
+	cfg := config.Default()
+	dense := cfg.Defaults
+	dense.TextSize = 4
+	cfg.SetPreset("Dense", dense)
+
+	model := NewMezzotoneModel()
+	model.SetConfig(cfg, "")
+	model.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+	model.currentActiveMenu = renderOptionsMenu
+	model.renderSettings.SetActive(0)
+	model.renderSettings.Items[1].Value = "6"
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if !model.modal.Visible || model.presetItem().Value != customPreset || model.renderSettings.Items[1].Value != "6" {
+		t.Fatalf("expected switching away from unsaved options to ask first")
+	}
+	// Declining keeps the options, confirming loads the preset.
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model.Update(cmd())
+	if model.modal.Visible || model.renderSettings.Items[1].Value != "6" {
+		t.Fatalf("expected the options to be kept")
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyRight})
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model.Update(cmd())
+	if got := renderSettingsFromItems(model.renderSettings.Items); got != dense || model.presetItem().Value != "Dense" {
+		t.Fatalf("expected the Dense preset after discarding, got %+v", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "render.txt")
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	model.currentActiveMenu = renderViewText
+	model.renderResult = &services.RenderResult{Grid: [][]rune{[]rune("new")}, Cols: 3, Rows: 1}
+	model.exportDialog.Open(path)
+	model.Update(ui.ExportRequestMsg{Path: path, Format: "TEXT"})
+	if !model.modal.Visible || !strings.Contains(ansi.Strip(model.View()), "already exists") {
+		t.Fatalf("expected an existing file to ask before overwriting")
+	}
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	model.Update(cmd())
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.HasPrefix(string(data), "new") || model.exportDialog.Visible {
+		t.Fatalf("expected the file to be overwritten, got %q (%v)", data, err)
+	}
+}
+
+func TestAnimatedExportRunsInTheBackgroundWithProgress(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "anim.gif")
+	palette := color.Palette{color.Black, color.White}
+	anim := &gif.GIF{}
+	for range 3 {
+		anim.Image = append(anim.Image, image.NewPaletted(image.Rect(0, 0, 16, 16), palette))
+		anim.Delay = append(anim.Delay, 10)
+	}
+	f, err := os.Create(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gif.EncodeAll(f, anim); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+	options, err := services.NewRenderOptions(8, 2.0, false, 0.6, false, false, "ASCII")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model := NewMezzotoneModel()
+	model.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+	model.currentActiveMenu = renderViewText
+	model.selectedFile = source
+	model.renderResult = &services.RenderResult{Grid: [][]rune{[]rune("x")}, Cols: 1, Rows: 1, Options: options}
+	path := filepath.Join(dir, "anim.cast")
+	model.exportDialog.Open(path)
+
+	_, cmd := model.Update(ui.ExportRequestMsg{Path: path, Format: "CAST"})
+	if cmd == nil || !strings.Contains(ansi.Strip(model.messageViewPort.View()), "Exporting") {
+		t.Fatalf("expected the export to start in the background")
+	}
+	if _, cmd := model.Update(ui.ExportRequestMsg{Path: path, Format: "CAST"}); cmd != nil {
+		t.Fatalf("expected a second export to wait for the first")
+	}
+
+	progress := 0
+	for cmd != nil {
+		msg := cmd()
+		if _, ok := msg.(exportProgressMsg); ok {
+			progress++
+		}
+		_, cmd = model.Update(msg)
+	}
+	if progress != 3 || model.exporting != nil || model.exportDialog.Visible {
+		t.Fatalf("expected 3 progress updates and a finished export, got %d", progress)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected the recording to be written: %v", err)
+	}
+	if !strings.Contains(ansi.Strip(model.messageViewPort.View()), "Saved CAST") {
+		t.Fatalf("expected the message pane to report the export, got %q", model.messageViewPort.View())
+	}
+}
@@ -31,17 +31,31 @@ func TestMezzotoneModelWindowResizeRendersView(t *testing.T) {
 	}
 }
 
-func TestMezzotoneModelEscFromFilePickerReturnsQuitCmd(t *testing.T) {
+func TestMezzotoneModelEscFromFilePickerAsksBeforeQuitting(t *testing.T) {
 	m := app.NewMezzotoneModel()
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
 
-	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
-	if cmd == nil {
-		t.Fatalf("expected quit command on esc from file picker")
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc}); cmd != nil {
+		t.Fatalf("expected esc from file picker to ask first")
+	}
+	if !strings.Contains(m.View(), "Leave Mezzotone?") {
+		t.Fatalf("expected the quit confirmation in the view")
+	}
+
+	// esc again declines.
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if _, cmd = m.Update(cmd()); cmd != nil || strings.Contains(m.View(), "Leave Mezzotone?") {
+		t.Fatalf("expected declining to keep the program running")
 	}
 
-	_ = updated
-	if msg := cmd(); msg == nil {
-		t.Fatalf("expected quit command to return a message")
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	_, cmd = m.Update(cmd())
+	if cmd == nil {
+		t.Fatalf("expected quit command after confirming")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Fatalf("expected quit command to return a quit message")
 	}
 }
 
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/ui"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// IDs of the confirmations asked in the modal.
+const (
+	quitModal           = "quit"
+	overwriteModal      = "overwrite"
+	discardOptionsModal = "discardOptions"
+)
+
+// pendingAction is what a confirmation waits on.
+type pendingAction struct {
+	export ui.ExportRequestMsg
+	preset string
+}
+
+func (m *MezzotoneModel) handleModalResult(msg ui.ModalResultMsg) tea.Cmd {
+	pending := m.pending
+	m.pending = pendingAction{}
+	if !msg.Confirmed {
+		return nil
+	}
+
+	switch msg.ID {
+	case quitModal:
+		m.stopPlayback()
+		return tea.Quit
+
+	case overwriteModal:
+		pending.export.Overwrite = true
+		return m.handleExportRequest(pending.export)
+
+	case discardOptionsModal:
+		before := itemValues(m.renderSettings.Items)
+		if err := m.ApplyPreset(pending.preset); err != nil {
+			m.updateMessageViewPortContent("⚠ "+err.Error(), true)
+			return nil
+		}
+		m.history.record(before, itemValues(m.renderSettings.Items))
+	}
+	return nil
+}
+
+// unsavedOptions reports whether the (custom) options differ from the defaults, so switching
+// to a preset would lose them.
+func (m *MezzotoneModel) unsavedOptions() bool {
+	return renderSettingsFromItems(m.renderSettings.Items) != m.cfg.Defaults
+}
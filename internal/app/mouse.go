@@ -0,0 +1,173 @@
+package app
+
+import (
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// mouseHorizontalStep is how many columns shift+wheel scrolls the render sideways.
+const mouseHorizontalStep = 4
+
+// handleMouse routes clicks and the wheel to the pane under the pointer. Clicking a pane that
+// does not have the focus moves to it first, like esc and enter would.
+func (m *MezzotoneModel) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	if m.exportDialog.Visible || m.modal.Visible || msg.Action != tea.MouseActionPress {
+		return nil
+	}
+
+	l := m.layout
+	if l.mode == layoutTabbed && !l.fullscreen && l.tabs.contains(msg.X, msg.Y) {
+		if menu, ok := tabAt(msg.X - l.tabs.x); ok && msg.Button == tea.MouseButtonLeft && !m.helpVisible {
+			return m.focusTab(menu)
+		}
+		return nil
+	}
+	if l.shows(paneRender, m.currentActiveMenu) && l.render.contains(msg.X, msg.Y) {
+		if tea.MouseEvent(msg).IsWheel() && !m.previewVisible() {
+			m.wheelRender(msg)
+		}
+		return nil
+	}
+	if m.helpVisible {
+		return nil
+	}
+
+	switch {
+	case l.shows(paneSettings, m.currentActiveMenu) && l.settings.contains(msg.X, msg.Y):
+		return m.settingsMouse(msg, l.settings)
+	case l.shows(panePicker, m.currentActiveMenu) && l.picker.contains(msg.X, msg.Y):
+		// The first row inside the border.
+		return m.filePickerMouse(msg, msg.Y-l.picker.y-1)
+	}
+	return nil
+}
+
+// wheelRender scrolls the render panes, sideways for shift+wheel and the horizontal wheel.
+func (m *MezzotoneModel) wheelRender(msg tea.MouseMsg) {
+	switch {
+	case msg.Button == tea.MouseButtonWheelLeft || msg.Shift && msg.Button == tea.MouseButtonWheelUp:
+		m.scrollRenderX(-mouseHorizontalStep)
+	case msg.Button == tea.MouseButtonWheelRight || msg.Shift && msg.Button == tea.MouseButtonWheelDown:
+		m.scrollRenderX(mouseHorizontalStep)
+	default:
+		m.scrollRenderPanes(func(v *viewport.Model) { *v, _ = v.Update(msg) })
+	}
+}
+
+// filePickerMouse handles the pointer on row of the file picker box, counted from the recent
+// files at its top. Clicking a file highlights it and clicking it again opens it.
+func (m *MezzotoneModel) filePickerMouse(msg tea.MouseMsg, row int) tea.Cmd {
+	if m.renderSettings.Editing {
+		return nil
+	}
+	if tea.MouseEvent(msg).IsWheel() {
+		if m.currentActiveMenu != filePickerMenu {
+			return nil
+		}
+		step := 1
+		if msg.Button == tea.MouseButtonWheelUp {
+			step = -1
+		}
+		if m.recent.focused {
+			m.moveRecent(step)
+		} else {
+			m.filePicker = m.filePicker.moveTo(m.filePicker.selected + step)
+		}
+		return m.updatePreview()
+	}
+	if msg.Button != tea.MouseButtonLeft || row < 0 {
+		return nil
+	}
+
+	if row < m.recentRows() {
+		i := m.recentFirst() + row - 1
+		if row == 0 || row > recentVisibleRows || i >= len(m.state.RecentFiles) {
+			return nil
+		}
+		focusCmd := m.focusMenu(filePickerMenu)
+		return tea.Batch(focusCmd, m.openRecent(m.state.RecentFiles[i]))
+	}
+	row -= m.recentRows()
+
+	entry, ok := m.filePicker.entryAt(row)
+	if !ok {
+		return nil
+	}
+
+	focusCmd := m.focusMenu(filePickerMenu)
+	m.recent.focused = false
+	if entry == m.filePicker.selected {
+		return tea.Batch(focusCmd, m.openHighlighted())
+	}
+	m.filePicker = m.filePicker.moveTo(entry)
+	return tea.Batch(focusCmd, m.updatePreview())
+}
+
+// openHighlighted opens the directory or picks the file highlighted in the file picker.
+func (m *MezzotoneModel) openHighlighted() tea.Cmd {
+	var (
+		cmd     tea.Cmd
+		path    string
+		allowed bool
+	)
+	m.filePicker, cmd, path, allowed = m.filePicker.open()
+	if path == "" {
+		return tea.Batch(cmd, m.updatePreview())
+	}
+	return tea.Batch(cmd, m.pickFile(path, allowed))
+}
+
+// settingsMouse passes the pointer to the settings panel in r. The panel only takes the focus
+// once a file is selected.
+func (m *MezzotoneModel) settingsMouse(msg tea.MouseMsg, r paneRect) tea.Cmd {
+	if m.selectedFile == "" {
+		return nil
+	}
+	if m.currentActiveMenu != renderOptionsMenu && tea.MouseEvent(msg).IsWheel() {
+		return nil
+	}
+	focusCmd := m.focusMenu(renderOptionsMenu)
+	msg.X -= r.x
+	msg.Y -= r.y
+	return tea.Batch(focusCmd, m.updateRenderSettings(msg))
+}
+
+// focusMenu moves to menu for a click into its pane.
+func (m *MezzotoneModel) focusMenu(menu int) tea.Cmd {
+	if m.currentActiveMenu == menu {
+		return nil
+	}
+	var cmd tea.Cmd
+	switch {
+	case m.currentActiveMenu == renderViewText:
+		m.stopPlayback()
+	case m.currentActiveMenu == filePickerMenu:
+		cmd = m.clearSixelPreview()
+	}
+	if menu == filePickerMenu {
+		m.renderSettings.ClearActive()
+	}
+	for m.currentActiveMenu > menu {
+		m.decrementCurrentActiveMenu()
+	}
+	for m.currentActiveMenu < menu {
+		m.incrementCurrentActiveMenu()
+	}
+	return cmd
+}
+
+// focusTab moves to menu for a click on its tab. The options need a selected file and the
+// render view a render.
+func (m *MezzotoneModel) focusTab(menu int) tea.Cmd {
+	switch {
+	case menu == renderOptionsMenu && m.selectedFile == "":
+		return nil
+	case menu == renderViewText && m.renderResult == nil:
+		return nil
+	case menu == renderOptionsMenu && m.currentActiveMenu != renderOptionsMenu:
+		cmd := m.focusMenu(menu)
+		m.renderSettings.SetActive(0)
+		return cmd
+	}
+	return m.focusMenu(menu)
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"image"
+	"time"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// playback drives a FrameSource in wall-clock time, rendering one frame per command.
+type playback struct {
+	id     int
+	source services.FrameSource
+	// done is closed when the playback stops, so a frame command still decoding or waiting
+	// returns without a message.
+	done      chan struct{}
+	options   services.RenderOptions
+	startedAt time.Time
+	frames    int
+	dropped   int
+}
+
+type playbackFrameMsg struct {
+	id     int
+	result *services.RenderResult
+	// frame is the decoded source, shown by the split view.
+	frame   image.Image
+	dropped int
+}
+
+type playbackDoneMsg struct {
+	id  int
+	err error
+}
+
+// nextFrameCmd decodes and renders the next due frame and delivers it at its timestamp.
+// Frames that are already more than one frame late are dropped to keep pace with the clock.
+func (p *playback) nextFrameCmd() tea.Cmd {
+	id, source, options, startedAt, done := p.id, p.source, p.options, p.startedAt, p.done
+
+	return func() tea.Msg {
+		dropped := 0
+		for {
+			if p.stopped() {
+				return nil
+			}
+			frame, err := source.Next()
+			if p.stopped() {
+				return nil
+			}
+			if err != nil {
+				return playbackDoneMsg{id: id, err: err}
+			}
+
+			if time.Until(startedAt.Add(frame.Timestamp)) < -frame.Duration {
+				dropped++
+				continue
+			}
+
+			result, err := services.RenderFrame(frame, options)
+			if err != nil {
+				return playbackDoneMsg{id: id, err: err}
+			}
+
+			if wait := time.Until(startedAt.Add(frame.Timestamp)); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-done:
+					timer.Stop()
+					return nil
+				}
+			}
+			return playbackFrameMsg{id: id, result: result, frame: frame.Image, dropped: dropped}
+		}
+	}
+}
+
+// stop ends the playback and closes its source. A frame command blocked reading the source
+// returns once the source is closed.
+func (p *playback) stop() {
+	close(p.done)
+	_ = p.source.Close()
+}
+
+func (p *playback) stopped() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,222 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/config"
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/ui"
+	"codeberg.org/JoaoGarcia/Mezzotone/pkg/ascii"
+)
+
+const (
+	presetKey = "preset"
+	// customPreset is selected while the settings match no saved preset.
+	customPreset = "(custom)"
+
+	savePresetPrompt = "savePreset"
+)
+
+// renderSettingsItems builds the settings panel items, starting from settings.
+func renderSettingsItems(settings config.RenderSettings) []ui.SettingItem {
+	runeMode := []string{"ASCII", "UNICODE", "DOTS", "RECTANGLES", "BARS", "LOADING"}
+	items := []ui.SettingItem{
+		{Label: "Preset", Key: presetKey, Type: ui.TypeEnum, Value: customPreset, Enum: []string{customPreset}},
+		{Label: "Text Size", Key: "textSize", Type: ui.TypeInt, Min: 1, Max: 100, Step: 1, Group: "Sampling"},
+		{
+			Label: "Font Aspect", Key: "fontAspect", Type: ui.TypeFloat,
+			Min: ascii.MinFontAspect, Max: ascii.MaxFontAspect, Step: 0.1, Group: "Sampling",
+		},
+		{Label: "Directional Render", Key: "directionalRender", Type: ui.TypeBool, Group: "Edges"},
+		{
+			Label: "Edge Threshold", Key: "edgeThreshold", Type: ui.TypeFloat, Min: 0, Max: 1, Step: 0.05, Group: "Edges",
+			ShowWhen: &ui.Condition{Key: "directionalRender", Value: "TRUE"},
+		},
+		{Label: "Reverse Chars", Key: "reverseChars", Type: ui.TypeBool, Group: "Glyphs"},
+		{Label: "High Contrast", Key: "highContrast", Type: ui.TypeBool, Group: "Glyphs"},
+		{Label: "Rune Mode", Key: "runeMode", Type: ui.TypeEnum, Enum: runeMode, Group: "Glyphs"},
+	}
+	applyRenderSettings(items, settings)
+	return items
+}
+
+func applyRenderSettings(items []ui.SettingItem, s config.RenderSettings) {
+	for i := range items {
+		item := &items[i]
+		switch item.Key {
+		case "textSize":
+			item.Value = strconv.Itoa(s.TextSize)
+		case "fontAspect":
+			item.Value = strconv.FormatFloat(s.FontAspect, 'f', -1, 64)
+		case "directionalRender":
+			item.Value = strings.ToUpper(strconv.FormatBool(s.DirectionalRender))
+		case "edgeThreshold":
+			item.Value = strconv.FormatFloat(s.EdgeThreshold, 'f', -1, 64)
+		case "reverseChars":
+			item.Value = strings.ToUpper(strconv.FormatBool(s.ReverseChars))
+		case "highContrast":
+			item.Value = strings.ToUpper(strconv.FormatBool(s.HighContrast))
+		case "runeMode":
+			item.Value = strings.ToUpper(s.RuneMode)
+		}
+	}
+}
+
+func renderSettingsFromItems(items []ui.SettingItem) config.RenderSettings {
+	var s config.RenderSettings
+	for _, item := range items {
+		switch item.Key {
+		case "textSize":
+			s.TextSize, _ = strconv.Atoi(item.Value)
+		case "fontAspect":
+			s.FontAspect, _ = strconv.ParseFloat(item.Value, 64)
+		case "directionalRender":
+			s.DirectionalRender, _ = strconv.ParseBool(item.Value)
+		case "edgeThreshold":
+			s.EdgeThreshold, _ = strconv.ParseFloat(item.Value, 64)
+		case "reverseChars":
+			s.ReverseChars, _ = strconv.ParseBool(item.Value)
+		case "highContrast":
+			s.HighContrast, _ = strconv.ParseBool(item.Value)
+		case "runeMode":
+			s.RuneMode = item.Value
+		}
+	}
+	return s
+}
+
+// SetConfig starts the settings panel from the config defaults. Presets saved from the TUI are
+// written back to path; an empty path keeps them for this session only.
+func (m *MezzotoneModel) SetConfig(cfg config.Config, path string) {
+	m.cfg, m.cfgPath = cfg, path
+	applyRenderSettings(m.renderSettings.Items, cfg.Defaults)
+	m.refreshPresetItem(customPreset)
+}
+
+// ApplyPreset loads the named preset into the settings panel.
+func (m *MezzotoneModel) ApplyPreset(name string) error {
+	settings, err := m.cfg.Preset(name)
+	if err != nil {
+		return err
+	}
+	applyRenderSettings(m.renderSettings.Items, settings)
+	m.refreshPresetItem(m.presetName(name))
+	return nil
+}
+
+// presetName returns the spelling name is saved under.
+func (m *MezzotoneModel) presetName(name string) string {
+	for _, saved := range m.cfg.PresetNames() {
+		if strings.EqualFold(saved, name) {
+			return saved
+		}
+	}
+	return name
+}
+
+func (m *MezzotoneModel) presetItem() *ui.SettingItem {
+	for i := range m.renderSettings.Items {
+		if m.renderSettings.Items[i].Key == presetKey {
+			return &m.renderSettings.Items[i]
+		}
+	}
+	return nil
+}
+
+// refreshPresetItem lists the saved presets in the selector and selects selected.
+func (m *MezzotoneModel) refreshPresetItem(selected string) {
+	item := m.presetItem()
+	if item == nil {
+		return
+	}
+	item.Enum = append([]string{customPreset}, m.cfg.PresetNames()...)
+	item.Value = selected
+}
+
+// syncPresetSelection runs after the settings panel handled a key. Choosing a preset loads it,
+// and editing any other setting away from the selected preset switches the selector to custom.
+func (m *MezzotoneModel) syncPresetSelection(previous string) {
+	item := m.presetItem()
+	if item == nil || item.Value == customPreset {
+		return
+	}
+	if item.Value != previous {
+		if previous == customPreset && m.unsavedOptions() {
+			m.pending.preset = item.Value
+			item.Value = customPreset
+			m.modal.Confirm(discardOptionsModal, "Discard options",
+				"The current options are not saved as a preset. Switch to "+m.pending.preset+" and discard them?", "Discard")
+			return
+		}
+		if err := m.ApplyPreset(item.Value); err != nil {
+			m.updateMessageViewPortContent("⚠ "+err.Error(), true)
+		}
+		return
+	}
+	if settings, err := m.cfg.Preset(item.Value); err != nil || settings != renderSettingsFromItems(m.renderSettings.Items) {
+		item.Value = customPreset
+	}
+}
+
+func (m *MezzotoneModel) openSavePresetPrompt() {
+	name := ""
+	if item := m.presetItem(); item != nil && item.Value != customPreset {
+		name = item.Value
+	}
+	m.modal.Prompt(savePresetPrompt, "Save current as preset", name)
+}
+
+// savePreset stores the current settings under name and writes the config file.
+func (m *MezzotoneModel) savePreset(name string) error {
+	if strings.EqualFold(name, customPreset) {
+		return fmt.Errorf("%s is reserved", customPreset)
+	}
+	settings := renderSettingsFromItems(m.renderSettings.Items)
+	if _, err := settings.RenderOptions(); err != nil {
+		return err
+	}
+
+	name = m.presetName(name)
+	previous, existed := m.cfg.Presets[name]
+	m.cfg.SetPreset(name, settings)
+	if m.cfgPath != "" {
+		if err := config.Save(m.cfgPath, m.cfg); err != nil {
+			if existed {
+				m.cfg.SetPreset(name, previous)
+			} else {
+				m.cfg.DeletePreset(name)
+			}
+			return err
+		}
+	}
+	m.refreshPresetItem(name)
+	return nil
+}
+
+func (m *MezzotoneModel) handlePromptSubmit(msg ui.PromptSubmitMsg) {
+	switch msg.ID {
+	case savePresetPrompt:
+		m.handleSavePresetSubmit(msg.Value)
+	case jumpPrompt:
+		m.handleJumpSubmit(msg.Value)
+	case findPrompt:
+		m.handleFindSubmit(msg.Value)
+	}
+}
+
+func (m *MezzotoneModel) handleSavePresetSubmit(name string) {
+	if err := m.savePreset(name); err != nil {
+		_ = services.Logger().Errorf("saving preset failed: %v", err)
+		m.modal.SetError(err.Error())
+		return
+	}
+	m.modal.Close()
+
+	where := "for this session"
+	if m.cfgPath != "" {
+		where = "to " + m.cfgPath
+	}
+	m.updateMessageViewPortContent(fmt.Sprintf("Saved preset %s %s", m.presetName(name), where), false)
+}
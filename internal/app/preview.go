@@ -0,0 +1,232 @@
+package app
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/termgfx"
+	"codeberg.org/JoaoGarcia/Mezzotone/pkg/ascii"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// previewMaxSide bounds the width and height of the decoded thumbnail kept in memory for the
+// file picker preview.
+const previewMaxSide = 1024
+
+// previewState is the thumbnail of the file highlighted in the file picker.
+type previewState struct {
+	path   string
+	image  image.Image
+	width  int
+	height int
+	err    error
+
+	rendered termgfx.Preview
+	// graphics is the escape sequence drawing rendered at placed. It is only built again when the
+	// preview or its place changes, View appends it as it is.
+	graphics string
+	placed   *previewPlacement
+}
+
+// previewPlacement is where, and whether, the preview image is drawn: the top left content cell
+// of the render pane (1-based) and the box of the image inside it.
+type previewPlacement struct {
+	visible          bool
+	paneRow, paneCol int
+	boxX, boxY       int
+}
+
+type previewLoadedMsg struct {
+	path          string
+	image         image.Image
+	width, height int
+	err           error
+}
+
+// loadPreviewCmd decodes path off the update loop and shrinks it to a thumbnail.
+func loadPreviewCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		f, err := os.Open(path)
+		if err != nil {
+			return previewLoadedMsg{path: path, err: err}
+		}
+		defer func() { _ = f.Close() }()
+
+		img, _, err := ascii.Decode(f)
+		if err != nil {
+			return previewLoadedMsg{path: path, err: err}
+		}
+		b := img.Bounds()
+		return previewLoadedMsg{
+			path:   path,
+			image:  termgfx.Thumbnail(img, previewMaxSide, previewMaxSide),
+			width:  b.Dx(),
+			height: b.Dy(),
+		}
+	}
+}
+
+// updatePreview starts loading the highlighted file when it changed and is a still image.
+func (m *MezzotoneModel) updatePreview() tea.Cmd {
+	if m.graphics.Protocol == termgfx.ProtocolNone {
+		return nil
+	}
+	path := m.highlightedFile()
+	if path == m.preview.path {
+		return nil
+	}
+	m.preview = previewState{path: path}
+	if path == "" || services.IsVideoFile(path) || !m.filePickerAllows(path) {
+		return nil
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return nil
+	}
+	return loadPreviewCmd(path)
+}
+
+func (m *MezzotoneModel) filePickerAllows(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, allowed := range m.filePicker.AllowedTypes {
+		if ext == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MezzotoneModel) handlePreviewLoaded(msg previewLoadedMsg) {
+	if msg.path != m.preview.path {
+		return
+	}
+	m.preview.image, m.preview.width, m.preview.height, m.preview.err = msg.image, msg.width, msg.height, msg.err
+	if msg.err != nil {
+		_ = services.Logger().Errorf("preview of %s failed: %v", msg.path, msg.err)
+	}
+	m.renderPreview()
+}
+
+// renderPreview fits the loaded thumbnail into the render pane, leaving a row for the caption.
+func (m *MezzotoneModel) renderPreview() {
+	m.preview.rendered = termgfx.Preview{}
+	m.preview.placed = nil
+	if m.preview.image == nil {
+		return
+	}
+	rendered, err := termgfx.NewPreview(m.graphics, m.preview.image, m.style.renderWidth-2, m.style.renderHeight-3)
+	if err != nil {
+		m.preview.err = err
+		return
+	}
+	m.preview.rendered = rendered
+}
+
+func (m *MezzotoneModel) previewVisible() bool {
+	return m.currentActiveMenu == filePickerMenu && !m.helpVisible && m.graphics.Protocol != termgfx.ProtocolNone && !m.modal.Visible &&
+		m.layout.shows(paneRender, m.currentActiveMenu)
+}
+
+// previewView lays the preview out in the render pane, leaving the box of previewBox for the
+// graphics escape sequence.
+func (m *MezzotoneModel) previewView() string {
+	w, h := m.style.renderWidth, m.style.renderHeight
+	p := m.preview
+
+	var caption string
+	switch {
+	case p.err != nil:
+		caption = "⚠ " + p.err.Error()
+	case p.image != nil:
+		caption = fmt.Sprintf("%s · %dx%d · %s", filepath.Base(p.path), p.width, p.height, m.graphics.Protocol)
+	case p.path != "" && services.IsVideoFile(p.path):
+		caption = filepath.Base(p.path) + " · video"
+	}
+	caption = ansi.Truncate(m.theme.FaintStyle().Render(caption), max(0, w), "…")
+
+	boxX, boxY := m.previewBox()
+
+	lines := make([]string, 0, h)
+	for range boxY {
+		lines = append(lines, "")
+	}
+	if p.rendered.Rows > 0 {
+		for _, line := range strings.Split(p.rendered.Text, "\n") {
+			lines = append(lines, strings.Repeat(" ", boxX)+line)
+		}
+		lines = append(lines, "")
+	}
+	lines = append(lines, strings.Repeat(" ", max(0, (w-lipgloss.Width(caption))/2))+caption)
+
+	return lipgloss.NewStyle().Width(w).Height(h).MaxHeight(h).Render(strings.Join(lines, "\n"))
+}
+
+// previewBox is the top left cell, inside the render pane, of the box the image is drawn in:
+// centered above the caption.
+func (m *MezzotoneModel) previewBox() (boxX, boxY int) {
+	r := m.preview.rendered
+	return max(0, (m.style.renderWidth-r.Cols)/2), max(0, (m.style.renderHeight-r.Rows-2)/2)
+}
+
+// placePreview builds the graphics escape sequence again when the preview moved, was shown or
+// hidden, or was rendered again.
+func (m *MezzotoneModel) placePreview() {
+	boxX, boxY := m.previewBox()
+	// The render pane content starts below its top border and right of its left border.
+	placement := previewPlacement{
+		visible: m.previewVisible(),
+		paneRow: m.layout.render.y + 2,
+		paneCol: m.layout.render.x + 2,
+		boxX:    boxX,
+		boxY:    boxY,
+	}
+	if m.preview.placed != nil && *m.preview.placed == placement {
+		return
+	}
+	m.preview.graphics = m.previewGraphics(placement)
+	m.preview.placed = &placement
+}
+
+// previewGraphics is the escape sequence that draws the preview image at p and puts the cursor
+// back. It is appended to the end of the frame so it is written after every other line of the
+// same frame and the Sixel pixels are not overwritten by the text of later lines.
+func (m *MezzotoneModel) previewGraphics(p previewPlacement) string {
+	row, col := p.paneRow+p.boxY, p.paneCol+p.boxX
+
+	switch m.graphics.Protocol {
+	case termgfx.ProtocolKitty:
+		if !p.visible || m.preview.rendered.Graphics == "" {
+			return termgfx.KittyDeleteAll
+		}
+		return termgfx.KittyDeleteAll + fmt.Sprintf("\x1b7\x1b[%d;%dH", row, col) + m.preview.rendered.Graphics + "\x1b8"
+
+	case termgfx.ProtocolSixel:
+		if !p.visible {
+			return ""
+		}
+		// Wipe what an earlier, larger preview left in the pane before drawing the new one.
+		// The caption row is skipped because it was already written in this frame.
+		captionRow := p.boxY
+		if m.preview.rendered.Rows > 0 {
+			captionRow += m.preview.rendered.Rows + 1
+		}
+		var b strings.Builder
+		b.WriteString("\x1b7")
+		for y := range m.style.renderHeight {
+			if y != captionRow {
+				fmt.Fprintf(&b, "\x1b[%d;%dH\x1b[%dX", p.paneRow+y, p.paneCol, m.style.renderWidth)
+			}
+		}
+		if m.preview.rendered.Graphics != "" {
+			fmt.Fprintf(&b, "\x1b[%d;%dH%s", row, col, m.preview.rendered.Graphics)
+		}
+		b.WriteString("\x1b8")
+		return b.String()
+	}
+	return ""
+}
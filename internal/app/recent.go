@@ -0,0 +1,204 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/config"
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// recentVisibleRows is how many recent files are listed above the file picker at once.
+const recentVisibleRows = 3
+
+// recentList is the cursor over m.state.RecentFiles shown at the top of the file picker.
+type recentList struct {
+	focused bool
+	cursor  int
+}
+
+// SetState restores the previous session: the last browsed directory, the recent files that
+// still exist and the last render options. Call it after SetConfig so the last preset is known.
+func (m *MezzotoneModel) SetState(state config.State) {
+	m.state = state
+
+	if info, err := os.Stat(state.LastDirectory); err == nil && info.IsDir() {
+		m.filePicker.CurrentDirectory = state.LastDirectory
+	}
+
+	m.state.RecentFiles = nil
+	for _, path := range state.RecentFiles {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			m.state.RecentFiles = append(m.state.RecentFiles, path)
+		}
+	}
+
+	if state.LastSettings != nil {
+		applyRenderSettings(m.renderSettings.Items, *state.LastSettings)
+		selected := customPreset
+		if preset, err := m.cfg.Preset(state.LastPreset); err == nil && preset == *state.LastSettings {
+			selected = m.presetName(state.LastPreset)
+		}
+		m.refreshPresetItem(selected)
+	}
+	m.applyLayout()
+}
+
+// SessionState is what should be saved for the next session. Options that do not validate are
+// not remembered, the previous ones are kept instead.
+func (m *MezzotoneModel) SessionState() config.State {
+	state := m.state
+	state.LastDirectory = m.filePicker.CurrentDirectory
+
+	settings := renderSettingsFromItems(m.renderSettings.Items)
+	if _, err := settings.RenderOptions(); err == nil {
+		state.LastSettings = &settings
+		state.LastPreset = ""
+		if item := m.presetItem(); item != nil && item.Value != customPreset {
+			state.LastPreset = item.Value
+		}
+	}
+	return state
+}
+
+// recentRows is the height of the recent files section: a title, the files and a gap.
+func (m *MezzotoneModel) recentRows() int {
+	if len(m.state.RecentFiles) == 0 {
+		return 0
+	}
+	return min(len(m.state.RecentFiles), recentVisibleRows) + 2
+}
+
+func (m *MezzotoneModel) toggleRecentFocus() {
+	if len(m.state.RecentFiles) == 0 {
+		m.recent.focused = false
+		return
+	}
+	m.recent.focused = !m.recent.focused
+	m.recent.cursor = min(m.recent.cursor, len(m.state.RecentFiles)-1)
+}
+
+// updateRecent handles keys while the recent files have the focus.
+func (m *MezzotoneModel) updateRecent(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+	switch {
+	case key.Matches(keyMsg, m.keys.PickerUp):
+		m.moveRecent(-1)
+	case key.Matches(keyMsg, m.keys.PickerDown):
+		m.moveRecent(+1)
+	case key.Matches(keyMsg, m.keys.PickerOpen):
+		return m.openRecent(m.state.RecentFiles[m.recent.cursor])
+	}
+	return m.updatePreview()
+}
+
+// moveRecent moves the cursor of the recent files by step, staying on the list.
+func (m *MezzotoneModel) moveRecent(step int) {
+	m.recent.cursor = max(0, min(len(m.state.RecentFiles)-1, m.recent.cursor+step))
+}
+
+// openRecent selects a recent file and points the file picker at its directory.
+func (m *MezzotoneModel) openRecent(path string) tea.Cmd {
+	if _, err := os.Stat(path); err != nil {
+		m.removeRecent(path)
+		m.updateMessageViewPortContent("⚠ "+err.Error(), true)
+		return nil
+	}
+	m.recent.focused = false
+	m.filePicker.CurrentDirectory = filepath.Dir(path)
+	return tea.Batch(m.filePicker.Init(), m.selectFile(path))
+}
+
+func (m *MezzotoneModel) removeRecent(path string) {
+	for i, p := range m.state.RecentFiles {
+		if p == path {
+			m.state.RecentFiles = append(m.state.RecentFiles[:i], m.state.RecentFiles[i+1:]...)
+			break
+		}
+	}
+	if len(m.state.RecentFiles) == 0 {
+		m.recent = recentList{}
+	}
+	m.recent.cursor = min(m.recent.cursor, max(0, len(m.state.RecentFiles)-1))
+	m.applyLayout()
+}
+
+// pickFile selects a file chosen in the file picker, or tells that its type is not allowed.
+func (m *MezzotoneModel) pickFile(path string, allowed bool) tea.Cmd {
+	if !allowed {
+		m.updateMessageViewPortContent("⚠ Selected file not allowed", true)
+		m.selectedFile = ""
+		_ = services.Logger().Info(fmt.Sprintf("Tried Selecting File: %s", path))
+		return nil
+	}
+	return m.selectFile(path)
+}
+
+// selectFile moves on to the render options for path and remembers it as recent.
+func (m *MezzotoneModel) selectFile(path string) tea.Cmd {
+	m.selectedFile = path
+	_ = services.Logger().Info("Selected File: " + m.selectedFile)
+	m.history.dropRenders()
+
+	m.state.AddRecent(path)
+	m.applyLayout()
+
+	m.renderSettings.SetActive(0)
+	m.renderSettings.Confirm = false
+	m.incrementCurrentActiveMenu()
+	return m.clearSixelPreview()
+}
+
+// highlightedFile is the file under the cursor of whichever list has the focus.
+func (m *MezzotoneModel) highlightedFile() string {
+	if m.recent.focused && m.recent.cursor < len(m.state.RecentFiles) {
+		return m.state.RecentFiles[m.recent.cursor]
+	}
+	return m.filePicker.highlighted()
+}
+
+// recentFirst is the first recent file listed, so that the cursor stays visible.
+func (m *MezzotoneModel) recentFirst() int {
+	return max(0, min(m.recent.cursor-recentVisibleRows+1, len(m.state.RecentFiles)-recentVisibleRows))
+}
+
+// recentView lists the recent files from recentFirst on.
+func (m *MezzotoneModel) recentView(width int) string {
+	files := m.state.RecentFiles
+	if len(files) == 0 {
+		return ""
+	}
+	faint := m.theme.FaintStyle()
+	selected := m.theme.SelectedStyle()
+
+	tab := m.keys.PickerRecent.Help().Key
+	title := "Recent " + faint.Render("· "+tab)
+	if m.recent.focused {
+		title = lipgloss.NewStyle().Bold(true).Render("Recent") + faint.Render(" · "+tab+" to browse")
+	}
+	lines := []string{title}
+
+	first := m.recentFirst()
+	for i := first; i < min(len(files), first+recentVisibleRows); i++ {
+		cursor := "  "
+		if m.recent.focused && i == m.recent.cursor {
+			cursor = "> "
+		}
+		line := cursor + filepath.Base(files[i]) + faint.Render(" "+filepath.Dir(files[i]))
+		line = ansi.Truncate(line, max(1, width), "…")
+		if m.recent.focused && i == m.recent.cursor {
+			line = selected.Render(ansi.Strip(line))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n") + "\n\n"
+}
@@ -0,0 +1,206 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const jumpPrompt = "jump"
+
+// renderNav is the horizontal scroll position and the rulers of the render view. The
+// viewport does not tell its horizontal offset, so it is kept here to be shown and jumped to.
+type renderNav struct {
+	x      int
+	rulers bool
+
+	// query is the text last searched for and match where it was last found, if anywhere.
+	query string
+	match *renderMatch
+}
+
+// shownResult is the render shown in the render view, B or A while flipped, and nil while it
+// shows the help, a preview or nothing.
+func (m *MezzotoneModel) shownResult() *services.RenderResult {
+	result := m.renderResult
+	if m.compare.flipped && m.compare.pinned != nil {
+		result = m.compare.pinned
+	}
+	if result == nil || m.helpVisible || m.previewVisible() {
+		return nil
+	}
+	return result
+}
+
+// renderGrid is the size in cells of the render shown in the render view, and false while it
+// shows the help, a preview or nothing.
+func (m *MezzotoneModel) renderGrid() (cols, rows int, ok bool) {
+	result := m.shownResult()
+	if result == nil {
+		return 0, 0, false
+	}
+	return result.Cols, result.Rows, true
+}
+
+// renderChrome is what the rulers and the status line take from the render pane: the width
+// of the row numbers and the lines above and below the render.
+func (m *MezzotoneModel) renderChrome() (gutter, top, bottom int) {
+	_, rows, ok := m.renderGrid()
+	if !ok {
+		return 0, 0, 0
+	}
+	if m.nav.rulers {
+		gutter, top = len(strconv.Itoa(max(1, rows)))+1, 1
+	}
+	return gutter, top, 1
+}
+
+// setRenderX scrolls both render panes to column x, kept inside the render.
+func (m *MezzotoneModel) setRenderX(x int) {
+	cols, _, ok := m.renderGrid()
+	if !ok {
+		return
+	}
+	m.nav.x = max(0, min(x, cols-m.renderView.Width))
+	m.renderView.SetXOffset(m.nav.x)
+	if m.splitActive() {
+		m.split.view.SetXOffset(m.nav.x)
+	}
+}
+
+// scrollRenderX scrolls the render panes by delta columns. The help has no grid, so the
+// viewport keeps track of its offset.
+func (m *MezzotoneModel) scrollRenderX(delta int) {
+	if _, _, ok := m.renderGrid(); ok {
+		m.setRenderX(m.nav.x + delta)
+		return
+	}
+	m.scrollRenderPanes(func(v *viewport.Model) {
+		if delta < 0 {
+			v.ScrollLeft(-delta)
+		} else {
+			v.ScrollRight(delta)
+		}
+	})
+}
+
+func (m *MezzotoneModel) openJumpPrompt() {
+	value := fmt.Sprintf("%d,%d", m.renderView.YOffset+1, m.nav.x+1)
+	m.modal.Prompt(jumpPrompt, "Jump to row,column", value)
+}
+
+// jumpTo scrolls the render so the 1-based row and column are at the top left. Zero keeps
+// the current position on that axis.
+func (m *MezzotoneModel) jumpTo(row, col int) {
+	if row > 0 {
+		m.scrollRenderPanes(func(v *viewport.Model) { v.SetYOffset(row - 1) })
+	}
+	if col > 0 {
+		m.setRenderX(col - 1)
+	}
+}
+
+// parseJump reads "row", "row,column" or ",column", all counted from 1.
+func parseJump(s string) (row, col int, err error) {
+	rowText, colText, _ := strings.Cut(strings.TrimSpace(s), ",")
+	for _, part := range []struct {
+		text  string
+		value *int
+	}{{rowText, &row}, {colText, &col}} {
+		text := strings.TrimSpace(part.text)
+		if text == "" {
+			continue
+		}
+		n, err := strconv.Atoi(text)
+		if err != nil || n < 1 {
+			return 0, 0, fmt.Errorf("%q is not a row or column number", text)
+		}
+		*part.value = n
+	}
+	if row == 0 && col == 0 {
+		return 0, 0, errors.New("expected row, row,column or ,column")
+	}
+	return row, col, nil
+}
+
+func (m *MezzotoneModel) handleJumpSubmit(value string) {
+	row, col, err := parseJump(value)
+	if err != nil {
+		m.modal.SetError(err.Error())
+		return
+	}
+	m.modal.Close()
+	m.jumpTo(row, col)
+}
+
+// decorateRender adds the rulers and the status line around content, the render pane as the
+// viewports draw it.
+func (m *MezzotoneModel) decorateRender(content string) string {
+	cols, rows, ok := m.renderGrid()
+	if !ok {
+		return content
+	}
+	faint := m.theme.FaintStyle()
+	gutter, top, _ := m.renderChrome()
+
+	// The rulers and the match follow the render, which is the right half of the split view.
+	left := 0
+	if m.splitActive() {
+		left = m.split.view.Width + 1
+	}
+
+	var lines []string
+	if top > 0 {
+		lines = append(lines, strings.Repeat(" ", gutter+left)+faint.Render(columnRuler(m.nav.x, m.renderView.Width)))
+	}
+	for i, line := range strings.Split(content, "\n") {
+		line = m.highlightMatch(line, m.renderView.YOffset+i, left)
+		if gutter > 0 {
+			label := ""
+			if row := m.renderView.YOffset + i + 1; row <= rows {
+				label = strconv.Itoa(row)
+			}
+			line = faint.Render(fmt.Sprintf("%*s ", gutter-1, label)) + line
+		}
+		lines = append(lines, line)
+	}
+
+	lastRow := min(rows, m.renderView.YOffset+m.renderView.Height)
+	lastCol := min(cols, m.nav.x+m.renderView.Width)
+	status := fmt.Sprintf("rows %d-%d of %d · cols %d-%d of %d · %d%%",
+		min(rows, m.renderView.YOffset+1), lastRow, rows,
+		min(cols, m.nav.x+1), lastCol, cols,
+		int(math.Round(m.renderView.ScrollPercent()*100)),
+	)
+	if n, i := m.matchPosition(); n > 0 {
+		status += fmt.Sprintf(" · match %d of %d", i, n)
+	}
+	lines = append(lines, faint.Render(ansi.Truncate(status, m.style.renderWidth, "…")))
+	return strings.Join(lines, "\n")
+}
+
+// columnRuler labels every tenth of width columns starting at column x, counted from 1, and
+// marks the fifths between them.
+func columnRuler(x, width int) string {
+	ruler := []rune(strings.Repeat(" ", max(0, width)))
+	for i := 0; i < width; i++ {
+		col := x + i + 1
+		switch {
+		case col%10 == 0:
+			for j, r := range strconv.Itoa(col) {
+				if i+j < width {
+					ruler[i+j] = r
+				}
+			}
+		case col%5 == 0 && ruler[i] == ' ':
+			ruler[i] = '·'
+		}
+	}
+	return string(ruler)
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const findPrompt = "find"
+
+// renderMatch is where a search match starts in the render grid, counted from 0.
+type renderMatch struct {
+	row, col int
+}
+
+// findMatches lists every place query starts in grid, row by row.
+func findMatches(grid [][]rune, query string) []renderMatch {
+	needle := []rune(query)
+	if len(needle) == 0 {
+		return nil
+	}
+	var matches []renderMatch
+	for row, cells := range grid {
+		for col := 0; col+len(needle) <= len(cells); col++ {
+			if slices.Equal(cells[col:col+len(needle)], needle) {
+				matches = append(matches, renderMatch{row: row, col: col})
+			}
+		}
+	}
+	return matches
+}
+
+func (m *MezzotoneModel) openFindPrompt() {
+	m.modal.Prompt(findPrompt, "Find in render", m.nav.query)
+}
+
+func (m *MezzotoneModel) handleFindSubmit(query string) {
+	if query == "" {
+		m.modal.SetError("type the text to find")
+		return
+	}
+	m.nav.query, m.nav.match = query, nil
+	if !m.findNext(+1) {
+		m.modal.SetError(fmt.Sprintf("%q is not in the render", query))
+		return
+	}
+	m.modal.Close()
+}
+
+// repeatFind moves to the next match of the last search, or the previous one when backwards.
+func (m *MezzotoneModel) repeatFind(backwards bool) {
+	if m.nav.query == "" {
+		m.updateMessageViewPortContent(fmt.Sprintf("⚠ Find a text with %s first", m.keys.Find.Help().Key), true)
+		return
+	}
+	dir := +1
+	if backwards {
+		dir = -1
+	}
+	if !m.findNext(dir) {
+		m.updateMessageViewPortContent(fmt.Sprintf("⚠ %q is not in the render", m.nav.query), true)
+	}
+}
+
+// findNext moves to the match after the current one, or before it for a negative dir, wrapping
+// around the render. Without a current match it starts at the top left of the view. It
+// reports whether the query is in the render at all.
+func (m *MezzotoneModel) findNext(dir int) bool {
+	result := m.shownResult()
+	if result == nil {
+		return false
+	}
+	matches := findMatches(result.Grid, m.nav.query)
+	if len(matches) == 0 {
+		m.nav.match = nil
+		return false
+	}
+
+	// A match right at the top left of the view comes after it.
+	from := renderMatch{row: m.renderView.YOffset, col: m.nav.x - 1}
+	if m.nav.match != nil {
+		from = *m.nav.match
+	}
+	before := func(a, b renderMatch) bool {
+		return a.row < b.row || a.row == b.row && a.col < b.col
+	}
+
+	var next renderMatch
+	if dir >= 0 {
+		next = matches[0]
+		for _, match := range matches {
+			if before(from, match) {
+				next = match
+				break
+			}
+		}
+	} else {
+		next = matches[len(matches)-1]
+		for i := len(matches) - 1; i >= 0; i-- {
+			if before(matches[i], from) {
+				next = matches[i]
+				break
+			}
+		}
+	}
+	m.nav.match = &next
+	m.revealMatch(next)
+	return true
+}
+
+// revealMatch scrolls the render panes until match is in view, centering it on each axis it
+// was outside of.
+func (m *MezzotoneModel) revealMatch(match renderMatch) {
+	h, w := m.renderView.Height, m.renderView.Width
+	if match.row < m.renderView.YOffset || match.row >= m.renderView.YOffset+h {
+		m.scrollRenderPanes(func(v *viewport.Model) { v.SetYOffset(max(0, match.row-h/2)) })
+	}
+	width := len([]rune(m.nav.query))
+	if match.col < m.nav.x || match.col+width > m.nav.x+w {
+		m.setRenderX(match.col - max(0, (w-width)/2))
+	}
+}
+
+// currentMatch is the current match while the shown render still has the query there.
+func (m *MezzotoneModel) currentMatch() (renderMatch, bool) {
+	result := m.shownResult()
+	match := m.nav.match
+	if result == nil || match == nil || match.row >= len(result.Grid) {
+		return renderMatch{}, false
+	}
+	needle, cells := []rune(m.nav.query), result.Grid[match.row]
+	if match.col+len(needle) > len(cells) || !slices.Equal(cells[match.col:match.col+len(needle)], needle) {
+		return renderMatch{}, false
+	}
+	return *match, true
+}
+
+// matchPosition is the number of matches in the shown render and which of them is the current
+// one, counted from 1. Both are 0 without a current match.
+func (m *MezzotoneModel) matchPosition() (n, i int) {
+	match, ok := m.currentMatch()
+	if !ok {
+		return 0, 0
+	}
+	matches := findMatches(m.shownResult().Grid, m.nav.query)
+	return len(matches), slices.Index(matches, match) + 1
+}
+
+// highlightMatch marks the current match on line, which draws render row row starting left
+// columns into the pane.
+func (m *MezzotoneModel) highlightMatch(line string, row, left int) string {
+	match, ok := m.currentMatch()
+	if !ok || match.row != row {
+		return line
+	}
+	start := left + max(0, match.col-m.nav.x)
+	end := left + min(m.renderView.Width, match.col+len([]rune(m.nav.query))-m.nav.x)
+	if end <= start {
+		return line
+	}
+	marked := m.theme.SelectedStyle().Render(ansi.Strip(ansi.Cut(line, start, end)))
+	return ansi.Cut(line, 0, start) + marked + ansi.TruncateLeft(line, end, "")
+}
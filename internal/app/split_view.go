@@ -0,0 +1,149 @@
+package app
+
+import (
+	"image"
+	"os"
+	"strings"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/termgfx"
+	"codeberg.org/JoaoGarcia/Mezzotone/pkg/ascii"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type splitMode int
+
+const (
+	splitOff splitMode = iota
+	// splitSource shows a half-block approximation of the source left of the render.
+	splitSource
+	// splitPinned shows the pinned render A left of the current render B.
+	splitPinned
+)
+
+// splitState is the left side of the split render view. Both sides share the cols x rows grid
+// of the render, so they scroll line for line.
+type splitState struct {
+	mode splitMode
+	view viewport.Model
+
+	// image is the decoded source of a still render, kept while path stays selected.
+	image  image.Image
+	path   string
+	source string
+}
+
+// splitActive reports whether the render pane is currently divided in two.
+func (m *MezzotoneModel) splitActive() bool {
+	return m.split.mode != splitOff && !m.helpVisible && m.splitContent() != ""
+}
+
+func (m *MezzotoneModel) splitContent() string {
+	switch m.split.mode {
+	case splitSource:
+		return m.split.source
+	case splitPinned:
+		return m.compare.content
+	}
+	return ""
+}
+
+// layoutRenderPanes gives both viewports equal halves of the render pane while the split is
+// active, and the whole pane to the render otherwise, less the rulers and the status line.
+// Before the first layout the viewports keep their height.
+func (m *MezzotoneModel) layoutRenderPanes() {
+	gutter, top, bottom := m.renderChrome()
+	width := max(1, m.style.renderWidth-gutter)
+	if m.style.renderHeight > 0 {
+		m.renderView.Height = max(1, m.style.renderHeight-top-bottom)
+	}
+	if !m.splitActive() {
+		m.renderView.Width = width
+	} else {
+		half := max(1, (width-1)/2)
+		m.renderView.Width = half
+		m.split.view.Width = half
+		m.split.view.Height = m.renderView.Height
+	}
+	m.setRenderX(m.nav.x)
+}
+
+// setSplitMode switches what the left side shows, turning the split off when mode is current.
+func (m *MezzotoneModel) setSplitMode(mode splitMode) {
+	if m.split.mode == mode {
+		mode = splitOff
+	}
+	m.split.mode = mode
+	m.split.view.SetContent(m.splitContent())
+	m.layoutRenderPanes()
+	m.split.view.SetYOffset(m.renderView.YOffset)
+}
+
+func (m *MezzotoneModel) toggleSplitView() {
+	m.setSplitMode(splitSource)
+	if m.split.mode == splitOff {
+		m.updateMessageViewPortContent("Split view off", false)
+		return
+	}
+
+	if m.playback != nil {
+		m.updateMessageViewPortContent("Split view on, source appears with the next frame", false)
+		return
+	}
+	if err := m.showStillSource(); err != nil {
+		m.updateMessageViewPortContent("⚠ "+err.Error(), true)
+		return
+	}
+	m.updateMessageViewPortContent("Split view on: source │ render", false)
+}
+
+// showStillSource decodes the selected image once and lays it out next to the current render.
+func (m *MezzotoneModel) showStillSource() error {
+	if m.split.path != m.selectedFile || m.split.image == nil {
+		f, err := os.Open(m.selectedFile)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+
+		img, _, err := ascii.Decode(f)
+		if err != nil {
+			return err
+		}
+		m.split.image, m.split.path = img, m.selectedFile
+	}
+	m.updateSourceView(m.split.image)
+	return nil
+}
+
+// updateSourceView redraws the source side for img on the grid of the current render.
+func (m *MezzotoneModel) updateSourceView(img image.Image) {
+	if m.renderResult == nil || img == nil {
+		m.split.source = ""
+		return
+	}
+	m.split.source = termgfx.RenderHalfBlocks(img, m.renderResult.Cols, m.renderResult.Rows)
+	if m.split.mode == splitSource {
+		m.split.view.SetContent(m.split.source)
+		m.layoutRenderPanes()
+		m.split.view.SetYOffset(m.renderView.YOffset)
+	}
+}
+
+// scrollRenderPanes applies a scroll to the render and, while split, to the left side as well.
+func (m *MezzotoneModel) scrollRenderPanes(scroll func(*viewport.Model)) {
+	scroll(&m.renderView)
+	if m.splitActive() {
+		scroll(&m.split.view)
+		m.split.view.SetYOffset(m.renderView.YOffset)
+	}
+}
+
+func (m *MezzotoneModel) splitView() string {
+	separator := m.theme.FaintStyle().Render(
+		strings.TrimSuffix(strings.Repeat("│\n", max(1, m.renderView.Height)), "\n"),
+	)
+	return lipgloss.NewStyle().Width(m.style.renderWidth).Render(
+		lipgloss.JoinHorizontal(lipgloss.Top, m.split.view.View(), separator, m.renderView.View()),
+	)
+}
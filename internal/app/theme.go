@@ -0,0 +1,118 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/config"
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/ui"
+	"github.com/charmbracelet/bubbles/filepicker"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SetTheme colors the model and its components with t.
+func (m *MezzotoneModel) SetTheme(t ui.Theme) {
+	m.theme = t
+	m.renderSettings.Theme = t
+	m.exportDialog.Theme = t
+	m.modal.Theme = t
+	m.filePicker.Styles = pickerStyles(m.filePicker.Styles, t)
+}
+
+// pickerStyles colors the file picker styles s with t, keeping their widths and padding. The
+// highlighted entry looks like the cursor row of the other panes.
+func pickerStyles(s filepicker.Styles, t ui.Theme) filepicker.Styles {
+	recolor := func(style, colors lipgloss.Style) lipgloss.Style {
+		return style.UnsetForeground().UnsetBold().Inherit(colors)
+	}
+	s.Cursor = recolor(s.Cursor, t.AccentStyle())
+	s.Selected = recolor(s.Selected, t.SelectedStyle().Bold(true))
+	s.DisabledCursor = recolor(s.DisabledCursor, t.FaintStyle())
+	s.DisabledSelected = recolor(s.DisabledSelected, t.FaintStyle().Reverse(true))
+	s.DisabledFile = recolor(s.DisabledFile, t.FaintStyle())
+	s.Directory = recolor(s.Directory, t.AccentStyle())
+	s.FileSize = recolor(s.FileSize, t.FaintStyle())
+	s.Permission = recolor(s.Permission, t.FaintStyle())
+	s.EmptyDirectory = recolor(s.EmptyDirectory, t.FaintStyle())
+	return s
+}
+
+// ResolveTheme finds the theme named in the config among the built-in and the user themes.
+// hasDarkBackground is only asked when the theme is "auto" or based on it.
+func ResolveTheme(cfg config.Config, hasDarkBackground func() bool) (ui.Theme, error) {
+	name := cfg.Theme
+	if name == "" {
+		name = ui.ThemeAuto
+	}
+	for themeName, colors := range cfg.Themes {
+		if strings.EqualFold(themeName, name) {
+			return userTheme(themeName, colors, hasDarkBackground)
+		}
+	}
+	t, ok := builtinTheme(name, hasDarkBackground)
+	if !ok {
+		return ui.Theme{}, fmt.Errorf("theme: unknown theme %q", name)
+	}
+	return t, nil
+}
+
+func builtinTheme(name string, hasDarkBackground func() bool) (ui.Theme, bool) {
+	if strings.EqualFold(name, ui.ThemeAuto) {
+		name = ui.ThemeLight
+		if hasDarkBackground() {
+			name = ui.ThemeDark
+		}
+	}
+	return ui.BuiltinTheme(name)
+}
+
+// userTheme is the base theme of colors with the colors that are set replaced.
+func userTheme(name string, colors config.ThemeColors, hasDarkBackground func() bool) (ui.Theme, error) {
+	base := colors.Base
+	if base == "" {
+		base = ui.ThemeAuto
+	}
+	t, ok := builtinTheme(base, hasDarkBackground)
+	if !ok {
+		return ui.Theme{}, fmt.Errorf("theme %q: unknown base theme %q", name, base)
+	}
+
+	roles := []struct {
+		role  string
+		value string
+		color *lipgloss.Color
+	}{
+		{"accent", colors.Accent, &t.Accent},
+		{"error", colors.Error, &t.Error},
+		{"border", colors.Border, &t.Border},
+		{"selection", colors.Selection, &t.Selection},
+		{"faint", colors.Faint, &t.Faint},
+	}
+	for _, r := range roles {
+		if r.value == "" {
+			continue
+		}
+		c, err := themeColor(r.value)
+		if err != nil {
+			return ui.Theme{}, fmt.Errorf("theme %q: %s: %w", name, r.role, err)
+		}
+		*r.color = c
+	}
+	return t, nil
+}
+
+// themeColor accepts an ANSI color number or anything ui.ParseColor does.
+func themeColor(s string) (lipgloss.Color, error) {
+	if n, err := strconv.Atoi(strings.TrimSpace(s)); err == nil {
+		if n < 0 || n > 255 {
+			return "", fmt.Errorf("ANSI color %d out of range 0-255", n)
+		}
+		return lipgloss.Color(strconv.Itoa(n)), nil
+	}
+	c, err := ui.ParseColor(s)
+	if err != nil {
+		return "", err
+	}
+	return lipgloss.Color(ui.FormatColor(c)), nil
+}
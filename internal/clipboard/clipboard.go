@@ -0,0 +1,101 @@
+package clipboard
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	systemclipboard "github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	"github.com/charmbracelet/x/term"
+)
+
+type Method string
+
+const (
+	MethodSystem Method = "system clipboard"
+	MethodOSC52  Method = "OSC 52"
+)
+
+// ErrNoTerminal is returned when OSC 52 is needed but the output is not a terminal, where the
+// sequence would be lost.
+var ErrNoTerminal = errors.New("no terminal to send OSC 52 to")
+
+// Environment is the subset of the process environment used to pick a copy method.
+type Environment struct {
+	Getenv func(string) string
+	// SystemCopy writes to the local clipboard; it is nil when none is available.
+	SystemCopy func(string) error
+	// Terminal receives the OSC 52 escape sequence; it is nil when there is no terminal.
+	Terminal io.Writer
+}
+
+// Terminal is the program's output with its writes serialized, so an OSC 52 sequence written
+// from a command never lands in the middle of a frame. Give it to tea.WithOutput; it keeps the
+// Fd of the file, so Bubble Tea still sees a terminal.
+type Terminal struct {
+	*os.File
+	mu sync.Mutex
+}
+
+func NewTerminal(f *os.File) *Terminal {
+	return &Terminal{File: f}
+}
+
+func (t *Terminal) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.File.Write(p)
+}
+
+// DefaultEnvironment uses the real environment, the OS clipboard and terminal for OSC 52,
+// unless it is redirected away from a terminal.
+func DefaultEnvironment(terminal *Terminal) Environment {
+	env := Environment{Getenv: os.Getenv}
+	if terminal != nil && term.IsTerminal(terminal.Fd()) {
+		env.Terminal = terminal
+	}
+	if !systemclipboard.Unsupported {
+		env.SystemCopy = systemclipboard.WriteAll
+	}
+	return env
+}
+
+// Copy puts text on the clipboard and reports which method was used.
+// Over SSH the local clipboard belongs to the remote host, so OSC 52 is used directly;
+// otherwise the system clipboard is tried first and OSC 52 is the fallback.
+func Copy(text string, env Environment) (Method, error) {
+	if !IsRemoteSession(env.Getenv) && env.SystemCopy != nil {
+		if err := env.SystemCopy(text); err == nil {
+			return MethodSystem, nil
+		}
+	}
+
+	if env.Terminal == nil {
+		return MethodOSC52, ErrNoTerminal
+	}
+	if _, err := osc52Sequence(text, env.Getenv).WriteTo(env.Terminal); err != nil {
+		return MethodOSC52, err
+	}
+	return MethodOSC52, nil
+}
+
+// IsRemoteSession reports whether the process runs inside an SSH session.
+func IsRemoteSession(getenv func(string) string) bool {
+	return getenv("SSH_TTY") != "" || getenv("SSH_CONNECTION") != "" || getenv("SSH_CLIENT") != ""
+}
+
+// osc52Sequence wraps the sequence in a passthrough when running inside tmux or screen,
+// which would otherwise swallow it.
+func osc52Sequence(text string, getenv func(string) string) osc52.Sequence {
+	seq := osc52.New(text)
+	switch {
+	case getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case getenv("STY") != "" || strings.HasPrefix(getenv("TERM"), "screen"):
+		seq = seq.Screen()
+	}
+	return seq
+}
@@ -0,0 +1,81 @@
+package clipboard_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/clipboard"
+)
+
+func envFor(vars map[string]string, system func(string) error, terminal *bytes.Buffer) clipboard.Environment {
+	return clipboard.Environment{
+		Getenv:     func(k string) string { return vars[k] },
+		SystemCopy: system,
+		Terminal:   terminal,
+	}
+}
+
+func TestCopyPrefersSystemClipboardLocally(t *testing.T) {
+	var copied string
+	var term bytes.Buffer
+	method, err := clipboard.Copy("art", envFor(nil, func(s string) error { copied = s; return nil }, &term))
+	if err != nil || method != clipboard.MethodSystem {
+		t.Fatalf("expected system clipboard, got %q %v", method, err)
+	}
+	if copied != "art" || term.Len() != 0 {
+		t.Fatalf("expected only the system clipboard to be written, got %q and %q", copied, term.String())
+	}
+}
+
+func TestCopyFallsBackToOSC52WhenSystemFails(t *testing.T) {
+	var term bytes.Buffer
+	method, err := clipboard.Copy("art", envFor(nil, func(string) error { return errors.New("no display") }, &term))
+	if err != nil || method != clipboard.MethodOSC52 {
+		t.Fatalf("expected OSC 52 fallback, got %q %v", method, err)
+	}
+	if !strings.Contains(term.String(), base64.StdEncoding.EncodeToString([]byte("art"))) {
+		t.Fatalf("expected base64 payload in %q", term.String())
+	}
+}
+
+func TestCopyUsesOSC52OverSSHInsideTmux(t *testing.T) {
+	var term bytes.Buffer
+	systemCalled := false
+	vars := map[string]string{"SSH_TTY": "/dev/pts/1", "TMUX": "/tmp/tmux-1000/default,1,0"}
+	method, err := clipboard.Copy("art", envFor(vars, func(string) error { systemCalled = true; return nil }, &term))
+	if err != nil || method != clipboard.MethodOSC52 {
+		t.Fatalf("expected OSC 52 over SSH, got %q %v", method, err)
+	}
+	if systemCalled {
+		t.Fatalf("remote sessions must not touch the host clipboard")
+	}
+	if !strings.HasPrefix(term.String(), "\x1bPtmux;") {
+		t.Fatalf("expected tmux passthrough, got %q", term.String())
+	}
+}
+
+func TestCopyFailsWithoutTerminalForOSC52(t *testing.T) {
+	env := clipboard.Environment{
+		Getenv:     func(string) string { return "" },
+		SystemCopy: func(string) error { return errors.New("no display") },
+	}
+	if _, err := clipboard.Copy("art", env); !errors.Is(err, clipboard.ErrNoTerminal) {
+		t.Fatalf("expected ErrNoTerminal when the output is not a terminal, got %v", err)
+	}
+}
+
+func TestDefaultEnvironmentSkipsRedirectedOutput(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatalf("failed creating file: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if env := clipboard.DefaultEnvironment(clipboard.NewTerminal(f)); env.Terminal != nil {
+		t.Fatal("expected a file not to be used as the OSC 52 terminal")
+	}
+}
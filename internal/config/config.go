@@ -0,0 +1,204 @@
+// Package config loads and saves the user's default render options and named presets.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
+	"codeberg.org/JoaoGarcia/Mezzotone/pkg/ascii"
+)
+
+// RenderSettings mirrors the render options of the settings panel.
+type RenderSettings struct {
+	TextSize          int     `json:"textSize"`
+	FontAspect        float64 `json:"fontAspect"`
+	DirectionalRender bool    `json:"directionalRender"`
+	EdgeThreshold     float64 `json:"edgeThreshold"`
+	ReverseChars      bool    `json:"reverseChars"`
+	HighContrast      bool    `json:"highContrast"`
+	RuneMode          string  `json:"runeMode"`
+}
+
+// Config is the content of the config file. Options missing from the file keep their
+// defaults, and options missing from a preset are taken from Defaults.
+type Config struct {
+	Defaults RenderSettings            `json:"defaults"`
+	Presets  map[string]RenderSettings `json:"presets,omitempty"`
+	// Keys rebinds TUI actions, e.g. "help": ["?"]. Actions not listed keep their keys.
+	Keys map[string][]string `json:"keys,omitempty"`
+	// Theme is a built-in theme, "auto", "dark", "light" or "high-contrast", or one of Themes.
+	// Empty is "auto".
+	Theme  string                 `json:"theme,omitempty"`
+	Themes map[string]ThemeColors `json:"themes,omitempty"`
+	// DisableMouse leaves the mouse to the terminal, so text can be selected natively.
+	DisableMouse bool `json:"disableMouse,omitempty"`
+}
+
+// ThemeColors is a user theme: the colors of Base, "auto" when empty, with the ones that are
+// set replaced. Colors are ANSI numbers 0-255 or hex like "#ff8800".
+type ThemeColors struct {
+	Base      string `json:"base,omitempty"`
+	Accent    string `json:"accent,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Border    string `json:"border,omitempty"`
+	Selection string `json:"selection,omitempty"`
+	Faint     string `json:"faint,omitempty"`
+}
+
+// ErrUnknownPreset is returned when a preset name is not in the config.
+var ErrUnknownPreset = errors.New("unknown preset")
+
+// DefaultSettings are the built-in render options.
+func DefaultSettings() RenderSettings {
+	options := ascii.DefaultOptions()
+	return RenderSettings{
+		TextSize:          options.TextSize,
+		FontAspect:        options.FontAspect,
+		DirectionalRender: options.DirectionalRender,
+		EdgeThreshold:     options.EdgeThreshold,
+		ReverseChars:      options.ReverseChars,
+		HighContrast:      options.HighContrast,
+		RuneMode:          string(options.RuneMode),
+	}
+}
+
+// RenderOptions validates the settings and converts them for the render service.
+func (s RenderSettings) RenderOptions() (services.RenderOptions, error) {
+	return services.NewRenderOptions(s.TextSize, s.FontAspect, s.DirectionalRender, s.EdgeThreshold, s.ReverseChars, s.HighContrast, s.RuneMode)
+}
+
+func Default() Config {
+	return Config{Defaults: DefaultSettings()}
+}
+
+// Path is the config file location, $XDG_CONFIG_HOME/mezzotone/config.json on Linux.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "mezzotone", "config.json"), nil
+}
+
+// Load reads the config at path. A missing file is not an error and yields Default().
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Default(), nil
+	}
+	if err != nil {
+		return Default(), err
+	}
+
+	cfg := Default()
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Default(), fmt.Errorf("parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Defaults     json.RawMessage            `json:"defaults"`
+		Presets      map[string]json.RawMessage `json:"presets"`
+		Keys         map[string][]string        `json:"keys"`
+		Theme        string                     `json:"theme"`
+		Themes       map[string]ThemeColors     `json:"themes"`
+		DisableMouse bool                       `json:"disableMouse"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	c.Defaults = DefaultSettings()
+	if raw.Defaults != nil {
+		if err := json.Unmarshal(raw.Defaults, &c.Defaults); err != nil {
+			return fmt.Errorf("defaults: %w", err)
+		}
+	}
+
+	c.Presets = nil
+	for name, presetData := range raw.Presets {
+		preset := c.Defaults
+		if err := json.Unmarshal(presetData, &preset); err != nil {
+			return fmt.Errorf("preset %q: %w", name, err)
+		}
+		c.SetPreset(name, preset)
+	}
+	c.Keys = raw.Keys
+	c.Theme = raw.Theme
+	c.Themes = raw.Themes
+	c.DisableMouse = raw.DisableMouse
+	return nil
+}
+
+// Save writes cfg to path, creating the directory, and replaces the old file only once the
+// new one is complete.
+func Save(path string, cfg Config) error {
+	return writeJSON(path, cfg)
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Preset looks a preset up by name, ignoring case.
+func (c Config) Preset(name string) (RenderSettings, error) {
+	for presetName, preset := range c.Presets {
+		if strings.EqualFold(presetName, name) {
+			return preset, nil
+		}
+	}
+	return RenderSettings{}, fmt.Errorf("%w: %s", ErrUnknownPreset, name)
+}
+
+func (c *Config) SetPreset(name string, settings RenderSettings) {
+	if c.Presets == nil {
+		c.Presets = make(map[string]RenderSettings)
+	}
+	c.Presets[name] = settings
+}
+
+func (c *Config) DeletePreset(name string) {
+	delete(c.Presets, name)
+}
+
+// PresetNames lists the presets in alphabetical order.
+func (c Config) PresetNames() []string {
+	names := make([]string, 0, len(c.Presets))
+	for name := range c.Presets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
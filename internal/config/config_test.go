@@ -0,0 +1,100 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/config"
+)
+
+func TestLoadMissingFileReturnsDefaults(t *testing.T) {
+	cfg, err := config.Load(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Defaults != config.DefaultSettings() || len(cfg.Presets) != 0 {
+		t.Fatalf("expected built-in defaults, got %+v", cfg)
+	}
+}
+
+func TestLoadFillsMissingFieldsFromDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+  "defaults": {"textSize": 6},
+  "presets": {"Dense": {"runeMode": "UNICODE"}}
+}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Defaults.TextSize != 6 || cfg.Defaults.FontAspect != config.DefaultSettings().FontAspect {
+		t.Fatalf("unexpected defaults: %+v", cfg.Defaults)
+	}
+
+	dense, err := cfg.Preset("dense")
+	if err != nil {
+		t.Fatalf("preset: %v", err)
+	}
+	if dense.RuneMode != "UNICODE" || dense.TextSize != 6 {
+		t.Fatalf("preset should inherit the file defaults, got %+v", dense)
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mezzotone", "config.json")
+	cfg := config.Default()
+	fine := cfg.Defaults
+	fine.TextSize, fine.DirectionalRender = 4, true
+	cfg.SetPreset("Fine", fine)
+	cfg.Keys = map[string][]string{"help": {"?"}}
+	cfg.Theme = "mine"
+	cfg.Themes = map[string]config.ThemeColors{"mine": {Base: "light", Accent: "#ff8800"}}
+	cfg.DisableMouse = true
+
+	if err := config.Save(path, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	loaded, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	got, err := loaded.Preset("Fine")
+	if err != nil || got != fine {
+		t.Fatalf("expected %+v, got %+v (%v)", fine, got, err)
+	}
+	if names := loaded.PresetNames(); len(names) != 1 || names[0] != "Fine" {
+		t.Fatalf("unexpected preset names %v", names)
+	}
+	if keys := loaded.Keys["help"]; len(keys) != 1 || keys[0] != "?" {
+		t.Fatalf("expected the help key to survive saving, got %v", loaded.Keys)
+	}
+	if loaded.Theme != "mine" || loaded.Themes["mine"] != cfg.Themes["mine"] {
+		t.Fatalf("expected the theme to survive saving, got %q %+v", loaded.Theme, loaded.Themes)
+	}
+	if !loaded.DisableMouse {
+		t.Fatal("expected disableMouse to survive saving")
+	}
+}
+
+func TestPresetUnknownName(t *testing.T) {
+	_, err := config.Default().Preset("missing")
+	if !errors.Is(err, config.ErrUnknownPreset) {
+		t.Fatalf("expected ErrUnknownPreset, got %v", err)
+	}
+}
+
+func TestLoadRejectsMalformedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"defaults": {"textSize": "big"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.Load(path); err == nil {
+		t.Fatal("expected a parse error")
+	}
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// MaxRecentFiles bounds the recent files kept in the state file.
+const MaxRecentFiles = 10
+
+// State is what Mezzotone remembers between sessions. Unlike Config it is rewritten on every
+// exit and not meant to be edited by hand.
+type State struct {
+	LastDirectory string   `json:"lastDirectory,omitempty"`
+	RecentFiles   []string `json:"recentFiles,omitempty"`
+	// LastSettings are the render options of the previous session, nil before the first exit.
+	LastSettings *RenderSettings `json:"lastSettings,omitempty"`
+	// LastPreset is the preset LastSettings were loaded from, if they still match it.
+	LastPreset string `json:"lastPreset,omitempty"`
+}
+
+// StatePath is the state file location: $XDG_STATE_HOME/mezzotone/state.json, falling back to
+// ~/.local/state on Unix and to the config directory on macOS and Windows.
+func StatePath() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		var err error
+		switch runtime.GOOS {
+		case "darwin", "windows":
+			dir, err = os.UserConfigDir()
+		default:
+			dir, err = os.UserHomeDir()
+			dir = filepath.Join(dir, ".local", "state")
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, "mezzotone", "state.json"), nil
+}
+
+// LoadState reads the state at path. A missing file yields an empty State.
+func LoadState(path string) (State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, err
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if state.LastSettings != nil {
+		// Fields added after the file was written keep their defaults.
+		settings := DefaultSettings()
+		if err := json.Unmarshal(data, &struct {
+			LastSettings *RenderSettings `json:"lastSettings"`
+		}{&settings}); err != nil {
+			return State{}, fmt.Errorf("parse %s: %w", path, err)
+		}
+		state.LastSettings = &settings
+	}
+	return state, nil
+}
+
+func SaveState(path string, state State) error {
+	return writeJSON(path, state)
+}
+
+// AddRecent moves path to the front of the recent files, dropping the oldest beyond
+// MaxRecentFiles.
+func (s *State) AddRecent(path string) {
+	recent := make([]string, 0, MaxRecentFiles)
+	recent = append(recent, path)
+	for _, p := range s.RecentFiles {
+		if p != path && len(recent) < MaxRecentFiles {
+			recent = append(recent, p)
+		}
+	}
+	s.RecentFiles = recent
+}
@@ -0,0 +1,55 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/config"
+)
+
+func TestStateRoundTripAndDefaultsForMissingSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte(`{"lastDirectory": "/pics", "lastSettings": {"textSize": 5}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := config.LoadState(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	want := config.DefaultSettings()
+	want.TextSize = 5
+	if state.LastDirectory != "/pics" || state.LastSettings == nil || *state.LastSettings != want {
+		t.Fatalf("unexpected state %+v", state)
+	}
+
+	state.AddRecent("/pics/a.png")
+	if err := config.SaveState(path, state); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	loaded, err := config.LoadState(path)
+	if err != nil || !slices.Equal(loaded.RecentFiles, []string{"/pics/a.png"}) {
+		t.Fatalf("unexpected reloaded state %+v (%v)", loaded, err)
+	}
+}
+
+func TestAddRecentMovesToFrontAndBoundsTheList(t *testing.T) {
+	var state config.State
+	for i := range config.MaxRecentFiles + 2 {
+		state.AddRecent("/f" + strconv.Itoa(i))
+	}
+	state.AddRecent("/f5")
+
+	if len(state.RecentFiles) != config.MaxRecentFiles {
+		t.Fatalf("expected %d recent files, got %d", config.MaxRecentFiles, len(state.RecentFiles))
+	}
+	if state.RecentFiles[0] != "/f5" || state.RecentFiles[1] != "/f11" {
+		t.Fatalf("unexpected order %v", state.RecentFiles)
+	}
+	if slices.Contains(state.RecentFiles, "/f0") || slices.Contains(state.RecentFiles, "/f1") {
+		t.Fatalf("expected the oldest entries to be dropped, got %v", state.RecentFiles)
+	}
+}
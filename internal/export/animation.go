@@ -0,0 +1,190 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"io"
+	"strings"
+	"time"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
+)
+
+const (
+	FormatCast   Format = "CAST"
+	FormatReplay Format = "REPLAY"
+	FormatGIF    Format = "GIF"
+)
+
+// AnimatedFormats lists the formats that export a whole frame sequence.
+func AnimatedFormats() []Format {
+	return []Format{FormatCast, FormatReplay, FormatGIF}
+}
+
+func (f Format) IsAnimated() bool {
+	return f == FormatCast || f == FormatReplay || f == FormatGIF
+}
+
+// WriteAnimation encodes every frame of animation to w in the given animated format.
+func WriteAnimation(w io.Writer, animation *services.Animation, format Format) error {
+	if animation == nil || len(animation.Frames) == 0 {
+		return errors.New("nothing rendered to export")
+	}
+
+	switch format {
+	case FormatCast:
+		return WriteAsciicast(w, animation)
+	case FormatReplay:
+		return WriteReplayScript(w, animation)
+	case FormatGIF:
+		return WriteGIF(w, animation)
+	default:
+		return fmt.Errorf("unsupported animated export format: %s", format)
+	}
+}
+
+// WriteAnimationFile exports animation to path with the same atomic semantics as WriteFile.
+func WriteAnimationFile(path string, animation *services.Animation, format Format, overwrite bool) error {
+	return writeFileAtomic(path, overwrite, func(w io.Writer) error {
+		return WriteAnimation(w, animation, format)
+	})
+}
+
+type asciicastHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Duration  float64           `json:"duration"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env"`
+}
+
+// WriteAsciicast writes an asciinema v2 recording: a JSON header line followed by one
+// [time, "o", data] event per frame, where each frame redraws the screen from the top left.
+func WriteAsciicast(w io.Writer, animation *services.Animation) error {
+	cols, rows := animationSize(animation)
+	bw := bufio.NewWriter(w)
+	encoder := json.NewEncoder(bw)
+
+	header := asciicastHeader{
+		Version:  2,
+		Width:    cols,
+		Height:   rows,
+		Duration: animation.Duration().Seconds(),
+		Title:    animation.Source.Path,
+		Env:      map[string]string{"TERM": "xterm-256color"},
+	}
+	if first := animation.Frames[0]; !first.RenderedAt.IsZero() {
+		header.Timestamp = first.RenderedAt.Unix()
+	}
+	if err := encoder.Encode(header); err != nil {
+		return err
+	}
+
+	var at time.Duration
+	for i, frame := range animation.Frames {
+		data := "\x1b[H" + terminalFrame(frame)
+		if i == 0 {
+			data = "\x1b[2J" + data
+		}
+		if err := encoder.Encode([]any{at.Seconds(), "o", data}); err != nil {
+			return err
+		}
+		at += animation.Delays[i]
+	}
+	return bw.Flush()
+}
+
+// WriteReplayScript writes a POSIX shell script that plays the animation in the terminal it
+// runs in. Frames are drawn from the cursor home position and separated by sleep calls; the
+// fractional sleeps work with GNU, BSD and busybox sleep.
+func WriteReplayScript(w io.Writer, animation *services.Animation) error {
+	cols, rows := animationSize(animation)
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "#!/bin/sh\n")
+	fmt.Fprintf(bw, "# Mezzotone replay: %d frames, %.2fs, needs a %dx%d terminal.\n",
+		len(animation.Frames), animation.Duration().Seconds(), cols, rows)
+	fmt.Fprintf(bw, "trap 'printf \"\\033[0m\\033[?25h\\n\"' EXIT\n")
+	fmt.Fprintf(bw, "trap 'exit 130' INT TERM\n")
+	fmt.Fprintf(bw, "printf '\\033[?25l\\033[2J'\n")
+
+	indent := ""
+	switch {
+	case animation.LoopCount == 0:
+		fmt.Fprintf(bw, "while :; do\n")
+		indent = "\t"
+	case animation.LoopCount > 0:
+		fmt.Fprintf(bw, "i=0\nwhile [ \"$i\" -le %d ]; do\n\ti=$((i + 1))\n", animation.LoopCount)
+		indent = "\t"
+	}
+
+	for i, frame := range animation.Frames {
+		fmt.Fprintf(bw, "%sprintf '%%s' '%s'\n", indent, shellQuote("\x1b[H"+terminalFrame(frame)))
+		if delay := animation.Delays[i]; delay > 0 {
+			fmt.Fprintf(bw, "%ssleep %.2f\n", indent, delay.Seconds())
+		}
+	}
+	if indent != "" {
+		fmt.Fprintf(bw, "done\n")
+	}
+	return bw.Flush()
+}
+
+// WriteGIF rasterizes every frame like WritePNG and encodes them as an animated GIF with the
+// source delays and loop count. Colors are mapped onto the Plan 9 palette.
+func WriteGIF(w io.Writer, animation *services.Animation) error {
+	out := &gif.GIF{LoopCount: animation.LoopCount}
+	indexCache := make(map[color.RGBA]uint8)
+
+	for i, frame := range animation.Frames {
+		out.Image = append(out.Image, palettize(RasterizeGrid(frame.Grid, frame.Colors), indexCache))
+		// GIF delays are in hundredths of a second.
+		out.Delay = append(out.Delay, int(animation.Delays[i].Round(10*time.Millisecond)/(10*time.Millisecond)))
+	}
+	return gif.EncodeAll(w, out)
+}
+
+// palettize maps a rasterized frame onto the fixed palette. Rasterized frames only contain the
+// cell colors and their shade blends, so caching the nearest index per color keeps this cheap.
+func palettize(src *image.RGBA, indexCache map[color.RGBA]uint8) *image.Paletted {
+	dst := image.NewPaletted(src.Bounds(), palette.Plan9)
+	for y := src.Rect.Min.Y; y < src.Rect.Max.Y; y++ {
+		for x := src.Rect.Min.X; x < src.Rect.Max.X; x++ {
+			c := src.RGBAAt(x, y)
+			index, ok := indexCache[c]
+			if !ok {
+				index = uint8(dst.Palette.Index(c))
+				indexCache[c] = index
+			}
+			dst.SetColorIndex(x, y, index)
+		}
+	}
+	return dst
+}
+
+// terminalFrame is the ANSI form of a frame with CRLF row endings, since a raw terminal
+// stream does not translate LF into a carriage return.
+func terminalFrame(frame *services.RenderResult) string {
+	return strings.ReplaceAll(ANSIString(frame), "\n", "\r\n")
+}
+
+func animationSize(animation *services.Animation) (cols, rows int) {
+	for _, frame := range animation.Frames {
+		cols = max(cols, frame.Cols)
+		rows = max(rows, frame.Rows)
+	}
+	return cols, rows
+}
+
+// shellQuote escapes s for use inside a single-quoted shell string.
+func shellQuote(s string) string {
+	return strings.ReplaceAll(s, "'", `'\''`)
+}
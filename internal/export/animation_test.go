@@ -0,0 +1,92 @@
+package export_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"image/gif"
+	"strings"
+	"testing"
+	"time"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/export"
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
+)
+
+func newTestAnimation() *services.Animation {
+	second := newTestResult()
+	second.Grid = [][]rune{[]rune("it'"), []rune("s  ")}
+	return &services.Animation{
+		Frames:    []*services.RenderResult{newTestResult(), second},
+		Delays:    []time.Duration{120 * time.Millisecond, 30 * time.Millisecond},
+		LoopCount: -1,
+	}
+}
+
+func TestWriteAsciicastEmitsHeaderAndTimedFrames(t *testing.T) {
+	var buf bytes.Buffer
+	if err := export.WriteAnimation(&buf, newTestAnimation(), export.FormatCast); err != nil {
+		t.Fatalf("cast export failed: %v", err)
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	scanner.Scan()
+	var header struct {
+		Version, Width, Height int
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil || header.Version != 2 || header.Width != 3 || header.Height != 2 {
+		t.Fatalf("unexpected header %q: %v", scanner.Text(), err)
+	}
+
+	var times []float64
+	for scanner.Scan() {
+		var event []any
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || len(event) != 3 || event[1] != "o" {
+			t.Fatalf("unexpected event %q: %v", scanner.Text(), err)
+		}
+		if data := event[2].(string); !strings.Contains(data, "\x1b[H") || !strings.Contains(data, "\r\n") {
+			t.Fatalf("expected cursor home and CRLF rows, got %q", data)
+		}
+		times = append(times, event[0].(float64))
+	}
+	if len(times) != 2 || times[0] != 0 || times[1] != 0.12 {
+		t.Fatalf("expected frames at 0s and 0.12s, got %v", times)
+	}
+}
+
+func TestWriteReplayScriptQuotesFramesAndSleeps(t *testing.T) {
+	var buf bytes.Buffer
+	if err := export.WriteAnimation(&buf, newTestAnimation(), export.FormatReplay); err != nil {
+		t.Fatalf("replay export failed: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "#!/bin/sh\n") {
+		t.Fatalf("expected a shell script, got %q", out)
+	}
+	if !strings.Contains(out, `'\''`) {
+		t.Fatalf("expected single quotes in frames to be escaped, got %q", out)
+	}
+	if !strings.Contains(out, "sleep 0.12\n") || !strings.Contains(out, "sleep 0.03\n") {
+		t.Fatalf("expected frame delays as sleeps, got %q", out)
+	}
+	if strings.Contains(out, "while") {
+		t.Fatalf("a play-once animation must not loop")
+	}
+}
+
+func TestWriteGIFPreservesDelaysAndLoop(t *testing.T) {
+	var buf bytes.Buffer
+	if err := export.WriteAnimation(&buf, newTestAnimation(), export.FormatGIF); err != nil {
+		t.Fatalf("gif export failed: %v", err)
+	}
+	decoded, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("invalid gif: %v", err)
+	}
+	if len(decoded.Image) != 2 || decoded.Delay[0] != 12 || decoded.Delay[1] != 3 || decoded.LoopCount != -1 {
+		t.Fatalf("unexpected gif frames %d delays %v loop %d", len(decoded.Image), decoded.Delay, decoded.LoopCount)
+	}
+	if b := decoded.Image[0].Bounds(); b.Dx() != 3*export.CellPixelWidth || b.Dy() != 2*export.CellPixelHeight {
+		t.Fatalf("unexpected gif frame size %v", b)
+	}
+}
@@ -0,0 +1,56 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
+)
+
+const ansiReset = "\x1b[0m"
+
+// WriteANSI writes the grid with 24-bit foreground colors, one escape per color run.
+// Without per-cell colors it falls back to plain text.
+func WriteANSI(w io.Writer, result *services.RenderResult) error {
+	bw := bufio.NewWriter(w)
+	for row := range result.Grid {
+		writeANSIRow(bw, result.Grid[row], rowColors(result, row))
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// ANSIString is the in-memory form of WriteANSI.
+func ANSIString(result *services.RenderResult) string {
+	var b strings.Builder
+	// strings.Builder never returns a write error.
+	_ = WriteANSI(&b, result)
+	return b.String()
+}
+
+func writeANSIRow(w *bufio.Writer, row []rune, colors []color.RGBA) {
+	var current color.RGBA
+	colored := false
+	for col, r := range row {
+		if colors != nil && col < len(colors) {
+			if c := colors[col]; !colored || c != current {
+				fmt.Fprintf(w, "\x1b[38;2;%d;%d;%dm", c.R, c.G, c.B)
+				current, colored = c, true
+			}
+		}
+		w.WriteRune(r)
+	}
+	if colored {
+		w.WriteString(ansiReset)
+	}
+}
+
+func rowColors(result *services.RenderResult, row int) []color.RGBA {
+	if row < len(result.Colors) {
+		return result.Colors[row]
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
+	"codeberg.org/JoaoGarcia/Mezzotone/pkg/ascii"
+)
+
+type Format string
+
+const (
+	FormatText Format = "TEXT"
+	FormatANSI Format = "ANSI"
+	FormatHTML Format = "HTML"
+	FormatPNG  Format = "PNG"
+	FormatJSON Format = "JSON"
+	FormatSVG  Format = "SVG"
+)
+
+// ErrExists is returned by WriteFile when the target exists and overwrite was not requested.
+var ErrExists = errors.New("file already exists")
+
+// Formats lists the still-image export formats in the order the TUI offers them.
+func Formats() []Format {
+	return []Format{FormatText, FormatANSI, FormatHTML, FormatSVG, FormatPNG, FormatJSON}
+}
+
+// Extension is the file extension, including the dot, used for format.
+func (f Format) Extension() string {
+	switch f {
+	case FormatANSI:
+		return ".ans"
+	case FormatHTML:
+		return ".html"
+	case FormatSVG:
+		return ".svg"
+	case FormatPNG:
+		return ".png"
+	case FormatJSON:
+		return ".json"
+	case FormatCast:
+		return ".cast"
+	case FormatReplay:
+		return ".sh"
+	case FormatGIF:
+		return ".gif"
+	default:
+		return ".txt"
+	}
+}
+
+// FormatFromPath guesses the export format from the file extension.
+func FormatFromPath(path string) (Format, bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, f := range append(Formats(), AnimatedFormats()...) {
+		if f.Extension() == ext {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+// Write encodes result to w in the given format.
+func Write(w io.Writer, result *services.RenderResult, format Format) error {
+	if result == nil {
+		return errors.New("nothing rendered to export")
+	}
+
+	switch format {
+	case FormatText:
+		return ascii.NewEncoder(w).Encode(result.Grid)
+	case FormatANSI:
+		return WriteANSI(w, result)
+	case FormatHTML:
+		return WriteHTML(w, result)
+	case FormatSVG:
+		return WriteSVG(w, result)
+	case FormatPNG:
+		return WritePNG(w, result)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// WriteFile exports result to path atomically: the data is written to a temporary file
+// in the same directory and renamed over the target only once it is complete.
+// Unless overwrite is set, an existing target yields ErrExists.
+func WriteFile(path string, result *services.RenderResult, format Format, overwrite bool) error {
+	return writeFileAtomic(path, overwrite, func(w io.Writer) error {
+		return Write(w, result, format)
+	})
+}
+
+func writeFileAtomic(path string, overwrite bool, write func(io.Writer) error) error {
+	if path == "" {
+		return errors.New("export path is empty")
+	}
+	// Without overwrite the target is created exclusively first, which claims the name in the
+	// same step as checking it, so a file appearing in the meantime is never replaced. The
+	// complete temporary file is then renamed over the empty claim.
+	claimed := false
+	if !overwrite {
+		claim, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("%w: %s", ErrExists, path)
+		}
+		if err != nil {
+			return err
+		}
+		_ = claim.Close()
+		claimed = true
+	}
+
+	committed := false
+	defer func() {
+		if !committed && claimed {
+			_ = os.Remove(path)
+		}
+	}()
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		if !committed {
+			_ = tmp.Close()
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	if err := write(tmp); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	committed = true
+
+	_ = services.Logger().Info(fmt.Sprintf("Exported render to %s", path))
+	return nil
+}
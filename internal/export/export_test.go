@@ -0,0 +1,137 @@
+package export_test
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/export"
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
+)
+
+func newTestResult() *services.RenderResult {
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+	return &services.RenderResult{
+		Grid:   [][]rune{[]rune("a<█"), []rune("▁⣿ ")},
+		Colors: [][]color.RGBA{{red, red, blue}, {blue, blue, blue}},
+		Cols:   3,
+		Rows:   2,
+	}
+}
+
+func TestWriteFileRefusesToOverwriteWithoutConfirmation(t *testing.T) {
+	dir := t.TempDir()
+	fresh := filepath.Join(dir, "fresh.txt")
+	if err := export.WriteFile(fresh, newTestResult(), export.FormatText, false); err != nil {
+		t.Fatalf("writing a new file failed: %v", err)
+	}
+	if data, _ := os.ReadFile(fresh); string(data) != "a<█\n▁⣿ \n" {
+		t.Fatalf("unexpected exported text %q", data)
+	}
+	if err := os.Remove(fresh); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "out.txt")
+	if err := os.WriteFile(path, []byte("keep me"), 0o644); err != nil {
+		t.Fatalf("failed writing existing file: %v", err)
+	}
+
+	err := export.WriteFile(path, newTestResult(), export.FormatText, false)
+	if !errors.Is(err, export.ErrExists) {
+		t.Fatalf("expected ErrExists, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "keep me" {
+		t.Fatalf("existing file must be untouched, got %q", data)
+	}
+
+	if err := export.WriteFile(path, newTestResult(), export.FormatText, true); err != nil {
+		t.Fatalf("overwrite failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "a<█\n▁⣿ \n" {
+		t.Fatalf("unexpected exported text %q", data)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Fatalf("expected temporary files to be cleaned up, found %d entries", len(entries))
+	}
+}
+
+func TestWriteANSIColorsRuns(t *testing.T) {
+	out := export.ANSIString(newTestResult())
+	if strings.Count(out, "\x1b[38;2;255;0;0m") != 1 {
+		t.Fatalf("expected a single escape for the red run, got %q", out)
+	}
+	if !strings.Contains(out, "\x1b[0m\n") {
+		t.Fatalf("expected rows to reset attributes, got %q", out)
+	}
+}
+
+func TestWriteHTMLEscapesAndColors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := export.Write(&buf, newTestResult(), export.FormatHTML); err != nil {
+		t.Fatalf("html export failed: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, `<span style="color:#ff0000">a&lt;</span>`) {
+		t.Fatalf("expected escaped red run, got %q", out)
+	}
+}
+
+func TestWritePNGMatchesGridSize(t *testing.T) {
+	var buf bytes.Buffer
+	if err := export.Write(&buf, newTestResult(), export.FormatPNG); err != nil {
+		t.Fatalf("png export failed: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("invalid png: %v", err)
+	}
+	if img.Bounds().Dx() != 3*export.CellPixelWidth || img.Bounds().Dy() != 2*export.CellPixelHeight {
+		t.Fatalf("unexpected png size %v", img.Bounds())
+	}
+
+	// The full block in row 0, col 2 must be painted blue.
+	r, g, b, _ := img.At(2*export.CellPixelWidth+3, 6).RGBA()
+	if r != 0 || g != 0 || b>>8 != 255 {
+		t.Fatalf("expected blue full block, got %d,%d,%d", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	if f, ok := export.FormatFromPath("art.HTML"); !ok || f != export.FormatHTML {
+		t.Fatalf("expected html format, got %q %v", f, ok)
+	}
+	if _, ok := export.FormatFromPath("art.docx"); ok {
+		t.Fatalf("expected unknown extension to be rejected")
+	}
+}
+
+func TestWriteSVGSizesCellsByFontAspect(t *testing.T) {
+	var buf bytes.Buffer
+	err := export.WriteSVG(&buf, newTestResult(), export.WithSVGCellHeight(23), export.WithSVGFontFamily("Iosevka, monospace"))
+	if err != nil {
+		t.Fatalf("svg export failed: %v", err)
+	}
+	out := buf.String()
+	// Without render options the default font aspect of 2.3 applies: 3x2 cells of 10x23.
+	if !strings.Contains(out, `viewBox="0 0 30 46"`) {
+		t.Fatalf("expected 30x46 viewBox, got %q", out)
+	}
+	if !strings.Contains(out, `font-family="Iosevka, monospace"`) || !strings.Contains(out, `<rect width="100%" height="100%" fill="#000000"/>`) {
+		t.Fatalf("expected font stack and background rect, got %q", out)
+	}
+	if !strings.Contains(out, `<text x="0" y="18.4" textLength="20" lengthAdjust="spacingAndGlyphs" fill="#ff0000">a&lt;</text>`) {
+		t.Fatalf("expected escaped red run at the first cell, got %q", out)
+	}
+	if strings.Count(out, "<text") != 3 {
+		t.Fatalf("expected one text element per non-blank color run, got %q", out)
+	}
+}
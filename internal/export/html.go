@@ -0,0 +1,65 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
+)
+
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { background: #000; margin: 0; }
+pre { font-family: "DejaVu Sans Mono", Menlo, Consolas, monospace; font-size: 10px; line-height: 1; color: #fff; margin: 1em; }
+</style>
+</head>
+<body>
+<pre>`
+
+const htmlFooter = `</pre>
+</body>
+</html>
+`
+
+// WriteHTML writes a standalone page with the grid in a <pre>, one <span> per color run.
+func WriteHTML(w io.Writer, result *services.RenderResult) error {
+	bw := bufio.NewWriter(w)
+
+	title := "Mezzotone render"
+	if result.Source.Path != "" {
+		title = result.Source.Path
+	}
+	fmt.Fprintf(bw, htmlHeader, html.EscapeString(title))
+
+	for row := range result.Grid {
+		writeHTMLRow(bw, result.Grid[row], rowColors(result, row))
+		bw.WriteByte('\n')
+	}
+
+	bw.WriteString(htmlFooter)
+	return bw.Flush()
+}
+
+func writeHTMLRow(w *bufio.Writer, row []rune, colors []color.RGBA) {
+	if len(colors) == 0 {
+		w.WriteString(html.EscapeString(string(row)))
+		return
+	}
+
+	start := 0
+	for col := 1; col <= len(row); col++ {
+		if col < len(row) && col < len(colors) && colors[col] == colors[start] {
+			continue
+		}
+		c := colors[min(start, len(colors)-1)]
+		fmt.Fprintf(w, `<span style="color:#%02x%02x%02x">%s</span>`, c.R, c.G, c.B, html.EscapeString(string(row[start:col])))
+		start = col
+	}
+}
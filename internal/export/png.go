@@ -0,0 +1,148 @@
+package export
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Pixel size of one character cell in rasterized output (matches basicfont.Face7x13).
+const (
+	CellPixelWidth  = 7
+	CellPixelHeight = 13
+)
+
+var rasterBackground = color.RGBA{A: 255}
+
+// WritePNG rasterizes the grid with a built-in bitmap font and encodes it as PNG.
+func WritePNG(w io.Writer, result *services.RenderResult) error {
+	return png.Encode(w, RasterizeGrid(result.Grid, result.Colors))
+}
+
+// RasterizeGrid draws every cell on a black canvas. Cells take their color from colors when
+// available and are white otherwise. Block, shade, bar, braille and line glyphs that the
+// bitmap font lacks are drawn procedurally so Unicode ramps survive rasterization.
+func RasterizeGrid(grid [][]rune, colors [][]color.RGBA) *image.RGBA {
+	cols := 0
+	for _, row := range grid {
+		cols = max(cols, len(row))
+	}
+	img := image.NewRGBA(image.Rect(0, 0, max(1, cols*CellPixelWidth), max(1, len(grid)*CellPixelHeight)))
+	draw.Draw(img, img.Bounds(), image.NewUniform(rasterBackground), image.Point{}, draw.Src)
+
+	face := basicfont.Face7x13
+	for rowIndex, row := range grid {
+		for colIndex, r := range row {
+			fg := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			if rowIndex < len(colors) && colIndex < len(colors[rowIndex]) {
+				fg = colors[rowIndex][colIndex]
+				fg.A = 255
+			}
+			cell := image.Rect(
+				colIndex*CellPixelWidth, rowIndex*CellPixelHeight,
+				(colIndex+1)*CellPixelWidth, (rowIndex+1)*CellPixelHeight,
+			)
+			if r == ' ' || drawProceduralGlyph(img, cell, r, fg) {
+				continue
+			}
+
+			dot := fixed.P(cell.Min.X, cell.Min.Y+face.Ascent)
+			dr, mask, maskp, _, ok := face.Glyph(dot, r)
+			if !ok {
+				fillFraction(img, cell, fg, 0.5)
+				continue
+			}
+			draw.DrawMask(img, dr, image.NewUniform(fg), image.Point{}, mask, maskp, draw.Over)
+		}
+	}
+	return img
+}
+
+func drawProceduralGlyph(img *image.RGBA, cell image.Rectangle, r rune, fg color.RGBA) bool {
+	w, h := cell.Dx(), cell.Dy()
+	switch {
+	case r == '█':
+		fillFraction(img, cell, fg, 1)
+	case r == '▓':
+		fillFraction(img, cell, fg, 0.75)
+	case r == '▒':
+		fillFraction(img, cell, fg, 0.5)
+	case r == '░':
+		fillFraction(img, cell, fg, 0.25)
+	case r >= '▁' && r <= '▇':
+		eighths := int(r-'▁') + 1
+		top := cell.Max.Y - h*eighths/8
+		fillRect(img, image.Rect(cell.Min.X, top, cell.Max.X, cell.Max.Y), fg)
+	case r == '■':
+		fillRect(img, cell.Inset(1), fg)
+	case r == '□':
+		inner := cell.Inset(1)
+		fillRect(img, inner, fg)
+		fillRect(img, inner.Inset(1), rasterBackground)
+	case r == '●':
+		fillRect(img, centeredRect(cell, w-1, w-1), fg)
+	case r == '•':
+		fillRect(img, centeredRect(cell, 3, 3), fg)
+	case r == '∙' || r == '·':
+		fillRect(img, centeredRect(cell, 2, 2), fg)
+	case r == '─':
+		fillRect(img, image.Rect(cell.Min.X, cell.Min.Y+h/2, cell.Max.X, cell.Min.Y+h/2+1), fg)
+	case r == '│':
+		fillRect(img, image.Rect(cell.Min.X+w/2, cell.Min.Y, cell.Min.X+w/2+1, cell.Max.Y), fg)
+	case r == '╱' || r == '╲':
+		for y := 0; y < h; y++ {
+			x := y * w / h
+			if r == '╱' {
+				x = w - 1 - x
+			}
+			img.SetRGBA(cell.Min.X+x, cell.Min.Y+y, fg)
+		}
+	case r >= 0x2800 && r <= 0x28FF:
+		drawBraille(img, cell, r, fg)
+	default:
+		return false
+	}
+	return true
+}
+
+// Braille dot bits in column-major order: dots 1-3 and 7 on the left, 4-6 and 8 on the right.
+var brailleDots = [8]image.Point{{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 1}, {1, 2}, {0, 3}, {1, 3}}
+
+func drawBraille(img *image.RGBA, cell image.Rectangle, r rune, fg color.RGBA) {
+	bits := int(r - 0x2800)
+	for i, p := range brailleDots {
+		if bits&(1<<i) == 0 {
+			continue
+		}
+		x := cell.Min.X + 1 + p.X*3
+		y := cell.Min.Y + 1 + p.Y*3
+		fillRect(img, image.Rect(x, y, x+2, y+2), fg)
+	}
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// fillFraction blends fg over the cell at the given coverage, which is how shade glyphs read.
+func fillFraction(img *image.RGBA, cell image.Rectangle, fg color.RGBA, coverage float64) {
+	blended := color.RGBA{
+		R: uint8(float64(fg.R)*coverage + float64(rasterBackground.R)*(1-coverage)),
+		G: uint8(float64(fg.G)*coverage + float64(rasterBackground.G)*(1-coverage)),
+		B: uint8(float64(fg.B)*coverage + float64(rasterBackground.B)*(1-coverage)),
+		A: 255,
+	}
+	fillRect(img, cell, blended)
+}
+
+func centeredRect(cell image.Rectangle, w, h int) image.Rectangle {
+	x := cell.Min.X + (cell.Dx()-w)/2
+	y := cell.Min.Y + (cell.Dy()-h)/2
+	return image.Rect(x, y, x+w, y+h)
+}
@@ -0,0 +1,138 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
+	"codeberg.org/JoaoGarcia/Mezzotone/pkg/ascii"
+)
+
+// DefaultSVGFontFamily is the CSS font stack used when none is configured.
+const DefaultSVGFontFamily = `"DejaVu Sans Mono", Menlo, Consolas, "Liberation Mono", monospace`
+
+type svgOptions struct {
+	fontFamily string
+	cellHeight float64
+	background color.RGBA
+}
+
+type SVGOption func(*svgOptions)
+
+// WithSVGFontFamily sets the CSS font-family list; it should end in a generic monospace family.
+func WithSVGFontFamily(fontFamily string) SVGOption {
+	return func(o *svgOptions) {
+		if fontFamily != "" {
+			o.fontFamily = fontFamily
+		}
+	}
+}
+
+// WithSVGCellHeight sets the user-unit height of one row, which is also the font size.
+func WithSVGCellHeight(height float64) SVGOption {
+	return func(o *svgOptions) {
+		if height > 0 {
+			o.cellHeight = height
+		}
+	}
+}
+
+func WithSVGBackground(c color.RGBA) SVGOption {
+	return func(o *svgOptions) {
+		o.background = c
+	}
+}
+
+// WriteSVG writes the grid as a scalable SVG: a background rect and one <text> per color run,
+// or per row without colors. Cells are fontAspect times taller than wide, matching the
+// sampling grid, and every run is stretched with textLength so columns line up in any font.
+func WriteSVG(w io.Writer, result *services.RenderResult, opts ...SVGOption) error {
+	options := svgOptions{fontFamily: DefaultSVGFontFamily, cellHeight: 16, background: rasterBackground}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	cols, rows := result.Cols, result.Rows
+	if rows == 0 {
+		rows = len(result.Grid)
+	}
+	for _, row := range result.Grid {
+		cols = max(cols, len(row))
+	}
+
+	fontAspect := result.Options.FontAspect()
+	if fontAspect <= 0 {
+		fontAspect = ascii.DefaultOptions().FontAspect
+	}
+	cellH := options.cellHeight
+	cellW := cellH / fontAspect
+	width, height := float64(cols)*cellW, float64(rows)*cellH
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n",
+		svgNumber(width), svgNumber(height), svgNumber(width), svgNumber(height))
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hexColor(options.background))
+	fmt.Fprintf(bw, `<g font-family="%s" font-size="%s" fill="#ffffff" xml:space="preserve">`+"\n",
+		html.EscapeString(options.fontFamily), svgNumber(cellH))
+
+	for row := range result.Grid {
+		// Baseline at 80% of the cell leaves room for descenders with line-height 1.
+		y := float64(row)*cellH + cellH*0.8
+		writeSVGRow(bw, result.Grid[row], rowColors(result, row), y, cellW)
+	}
+
+	bw.WriteString("</g>\n</svg>\n")
+	return bw.Flush()
+}
+
+func writeSVGRow(w *bufio.Writer, row []rune, colors []color.RGBA, y, cellW float64) {
+	writeRun := func(start, end int, fill string) {
+		run := row[start:end]
+		if isBlank(run) {
+			return
+		}
+		fmt.Fprintf(w, `<text x="%s" y="%s" textLength="%s" lengthAdjust="spacingAndGlyphs"%s>%s</text>`+"\n",
+			svgNumber(float64(start)*cellW), svgNumber(y), svgNumber(float64(len(run))*cellW), fill,
+			html.EscapeString(string(run)))
+	}
+
+	if len(colors) == 0 {
+		writeRun(0, len(row), "")
+		return
+	}
+
+	start := 0
+	for col := 1; col <= len(row); col++ {
+		if col < len(row) && col < len(colors) && colors[col] == colors[start] {
+			continue
+		}
+		writeRun(start, col, ` fill="`+hexColor(colors[min(start, len(colors)-1)])+`"`)
+		start = col
+	}
+}
+
+func isBlank(run []rune) bool {
+	for _, r := range run {
+		if r != ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// svgNumber formats coordinates with at most three decimals and no trailing zeros.
+func svgNumber(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 3, 64)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
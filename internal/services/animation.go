@@ -0,0 +1,175 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/pkg/ascii"
+)
+
+// ErrNotAnimated is returned when an animated export is requested for a source without frames.
+var ErrNotAnimated = errors.New("animated export needs a GIF source")
+
+// Animation is a rendered frame sequence together with the timing of its source.
+type Animation struct {
+	Frames []*RenderResult
+	// Delays holds how long each frame stays on screen, indexed like Frames.
+	Delays []time.Duration
+	// LoopCount follows the GIF convention: 0 loops forever, -1 plays once, n repeats n times.
+	LoopCount int
+	Source    SourceInfo
+}
+
+func (a *Animation) Duration() time.Duration {
+	var total time.Duration
+	for _, d := range a.Delays {
+		total += d
+	}
+	return total
+}
+
+func IsGIFFile(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".gif")
+}
+
+// RenderAnimation renders every frame of the GIF at path.
+func RenderAnimation(path string, renderOptions RenderOptions) (*Animation, error) {
+	return RenderAnimationProgress(path, renderOptions, nil)
+}
+
+// RenderAnimationProgress is RenderAnimation calling progress, when it is not nil, after each
+// rendered frame with the number of frames rendered so far and in total.
+func RenderAnimationProgress(path string, renderOptions RenderOptions, progress func(rendered, total int)) (*Animation, error) {
+	if !IsGIFFile(path) {
+		return nil, ErrNotAnimated
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	animation, err := renderAnimation(f, renderOptions, progress)
+	if err != nil {
+		return nil, err
+	}
+	animation.Source.Path = path
+	for _, frame := range animation.Frames {
+		frame.Source.Path = path
+	}
+	return animation, nil
+}
+
+// RenderAnimationReader decodes a GIF from r and renders each composited frame.
+func RenderAnimationReader(r io.Reader, renderOptions RenderOptions) (*Animation, error) {
+	return renderAnimation(r, renderOptions, nil)
+}
+
+func renderAnimation(r io.Reader, renderOptions RenderOptions, progress func(rendered, total int)) (*Animation, error) {
+	frames, loopCount, err := DecodeGIFFrames(r, renderOptions.asciiOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	animation := &Animation{
+		Frames:    make([]*RenderResult, 0, len(frames)),
+		Delays:    make([]time.Duration, 0, len(frames)),
+		LoopCount: loopCount,
+	}
+	for _, frame := range frames {
+		result, err := RenderFrame(frame, renderOptions)
+		if err != nil {
+			return nil, err
+		}
+		result.Source.Format = "gif"
+		animation.Frames = append(animation.Frames, result)
+		animation.Delays = append(animation.Delays, frame.Duration)
+		if progress != nil {
+			progress(len(animation.Frames), len(frames))
+		}
+	}
+	animation.Source = animation.Frames[0].Source
+
+	_ = Logger().Info(fmt.Sprintf("Rendered %d gif frames, %s total", len(frames), animation.Duration()))
+	return animation, nil
+}
+
+// DecodeGIFFrames decodes every frame of a GIF and composites it onto the logical screen,
+// honouring each frame's disposal method, so the returned images look like a viewer shows them.
+// The input and pixel limits of options apply to the whole file and to the logical screen.
+func DecodeGIFFrames(r io.Reader, options ascii.Options) ([]Frame, int, error) {
+	if options.MaxInputBytes > 0 {
+		r = io.LimitReader(r, options.MaxInputBytes+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	if options.MaxInputBytes > 0 && int64(len(data)) > options.MaxInputBytes {
+		return nil, 0, fmt.Errorf("%w: input is larger than %d bytes", ascii.ErrInputTooLarge, options.MaxInputBytes)
+	}
+
+	config, err := gif.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, fmt.Errorf("decode gif: %w", err)
+	}
+	if options.MaxPixels > 0 && config.Width*config.Height > options.MaxPixels {
+		return nil, 0, fmt.Errorf("%w: %dx%d pixels", ascii.ErrInputTooLarge, config.Width, config.Height)
+	}
+
+	decoded, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, fmt.Errorf("decode gif: %w", err)
+	}
+	if len(decoded.Image) == 0 {
+		return nil, 0, errors.New("decode gif: no frames")
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, config.Width, config.Height))
+	frames := make([]Frame, 0, len(decoded.Image))
+	var timestamp time.Duration
+	for i, paletted := range decoded.Image {
+		var disposal byte
+		if i < len(decoded.Disposal) {
+			disposal = decoded.Disposal[i]
+		}
+
+		var previous *image.RGBA
+		if disposal == gif.DisposalPrevious {
+			previous = cloneRGBA(canvas)
+		}
+
+		draw.Draw(canvas, paletted.Bounds(), paletted, paletted.Bounds().Min, draw.Over)
+
+		var delay time.Duration
+		if i < len(decoded.Delay) {
+			// GIF delays are in hundredths of a second.
+			delay = time.Duration(decoded.Delay[i]) * 10 * time.Millisecond
+		}
+		frames = append(frames, Frame{Image: cloneRGBA(canvas), Timestamp: timestamp, Duration: delay})
+		timestamp += delay
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, paletted.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+	return frames, decoded.LoopCount, nil
+}
+
+func cloneRGBA(src *image.RGBA) *image.RGBA {
+	dst := image.NewRGBA(src.Rect)
+	copy(dst.Pix, src.Pix)
+	return dst
+}
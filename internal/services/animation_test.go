@@ -0,0 +1,88 @@
+package services_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+	"time"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
+	"codeberg.org/JoaoGarcia/Mezzotone/pkg/ascii"
+)
+
+var gifTestPalette = color.Palette{color.Transparent, color.White, color.RGBA{R: 255, A: 255}}
+
+func palettedRect(rect image.Rectangle, index uint8) *image.Paletted {
+	img := image.NewPaletted(rect, gifTestPalette)
+	for i := range img.Pix {
+		img.Pix[i] = index
+	}
+	return img
+}
+
+// encodeTestGIF builds a 4x4 GIF: a white frame, then a red 2x2 patch disposed to background.
+func encodeTestGIF(t *testing.T) []byte {
+	t.Helper()
+	anim := &gif.GIF{
+		Image: []*image.Paletted{
+			palettedRect(image.Rect(0, 0, 4, 4), 1),
+			palettedRect(image.Rect(0, 0, 2, 2), 2),
+			palettedRect(image.Rect(2, 2, 4, 4), 2),
+		},
+		Delay:     []int{10, 25, 7},
+		Disposal:  []byte{gif.DisposalNone, gif.DisposalBackground, gif.DisposalNone},
+		LoopCount: 3,
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		t.Fatalf("failed encoding gif fixture: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeGIFFramesCompositesWithDisposal(t *testing.T) {
+	frames, loopCount, err := services.DecodeGIFFrames(bytes.NewReader(encodeTestGIF(t)), ascii.DefaultOptions())
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if len(frames) != 3 || loopCount != 3 {
+		t.Fatalf("expected 3 frames looping 3 times, got %d frames loop %d", len(frames), loopCount)
+	}
+
+	// Frame 1 keeps the white frame 0 around the red patch.
+	if r, g, _, _ := frames[1].Image.At(0, 0).RGBA(); r>>8 != 255 || g != 0 {
+		t.Fatalf("expected red patch in frame 1")
+	}
+	if _, g, _, _ := frames[1].Image.At(3, 3).RGBA(); g>>8 != 255 {
+		t.Fatalf("expected frame 0 to show through in frame 1")
+	}
+	// Frame 1 was disposed to background, so its patch is cleared in frame 2.
+	if _, _, _, a := frames[2].Image.At(0, 0).RGBA(); a != 0 {
+		t.Fatalf("expected disposed area to be transparent in frame 2")
+	}
+
+	wantDelays := []time.Duration{100 * time.Millisecond, 250 * time.Millisecond, 70 * time.Millisecond}
+	var wantTimestamp time.Duration
+	for i, frame := range frames {
+		if frame.Duration != wantDelays[i] || frame.Timestamp != wantTimestamp {
+			t.Fatalf("frame %d: got duration %s at %s", i, frame.Duration, frame.Timestamp)
+		}
+		wantTimestamp += wantDelays[i]
+	}
+}
+
+func TestRenderAnimationReaderRendersEveryFrame(t *testing.T) {
+	opts := mustRenderOptions(t, 1, 1, false, 0.6, false, false, "ASCII")
+	animation, err := services.RenderAnimationReader(bytes.NewReader(encodeTestGIF(t)), opts)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if len(animation.Frames) != 3 || animation.Duration() != 420*time.Millisecond {
+		t.Fatalf("expected 3 frames over 420ms, got %d over %s", len(animation.Frames), animation.Duration())
+	}
+	if animation.Source.Format != "gif" || animation.Frames[0].Cols != 4 {
+		t.Fatalf("unexpected metadata %+v, %d cols", animation.Source, animation.Frames[0].Cols)
+	}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"time"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/pkg/ascii"
+)
+
+// RenderResult is a finished render with everything needed to display, export or inspect it.
+type RenderResult struct {
+	Grid [][]rune
+	// Colors and Luminance are optional per-cell data, indexed like Grid.
+	Colors    [][]color.RGBA
+	Luminance [][]float64
+
+	Source SourceInfo
+
+	Cols, Rows            int
+	CellWidth, CellHeight float64
+	Edges                 ascii.EdgeStats
+	Options               RenderOptions
+
+	RenderedAt time.Time
+	Duration   time.Duration
+}
+
+// SourceInfo describes the decoded input image.
+type SourceInfo struct {
+	// Path is empty when the image was read from a stream.
+	Path   string `json:"path,omitempty"`
+	Format string `json:"format"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+func newRenderResult(result *ascii.Result, options RenderOptions, source SourceInfo, startedAt time.Time) *RenderResult {
+	return &RenderResult{
+		Grid:       result.Grid,
+		Colors:     result.Colors,
+		Luminance:  result.Luminance,
+		Source:     source,
+		Cols:       result.Cols,
+		Rows:       result.Rows,
+		CellWidth:  result.CellWidth,
+		CellHeight: result.CellHeight,
+		Edges:      result.Edges,
+		Options:    options,
+		RenderedAt: startedAt,
+		Duration:   time.Since(startedAt),
+	}
+}
+
+func (r *RenderResult) String() string {
+	if r == nil {
+		return ""
+	}
+	return ImageRuneArrayIntoString(r.Grid)
+}
+
+// Summary is a one-line human description used in the status area and logs.
+func (r *RenderResult) Summary() string {
+	return fmt.Sprintf(
+		"%dx%d cells from %dx%d %s in %s",
+		r.Cols, r.Rows, r.Source.Width, r.Source.Height, r.Source.Format, r.Duration.Round(time.Millisecond),
+	)
+}
+
+type renderResultJSON struct {
+	Source     SourceInfo        `json:"source"`
+	Cols       int               `json:"cols"`
+	Rows       int               `json:"rows"`
+	CellWidth  float64           `json:"cellWidth"`
+	CellHeight float64           `json:"cellHeight"`
+	Options    renderOptionsJSON `json:"options"`
+	Edges      edgeStatsJSON     `json:"edges"`
+	RenderedAt time.Time         `json:"renderedAt"`
+	DurationMs float64           `json:"durationMs"`
+	Lines      []string          `json:"lines"`
+	Colors     [][]string        `json:"colors,omitempty"`
+	Luminance  [][]float64       `json:"luminance,omitempty"`
+}
+
+type renderOptionsJSON struct {
+	TextSize          int     `json:"textSize"`
+	FontAspect        float64 `json:"fontAspect"`
+	DirectionalRender bool    `json:"directionalRender"`
+	EdgeThreshold     float64 `json:"edgeThreshold"`
+	ReverseChars      bool    `json:"reverseChars"`
+	HighContrast      bool    `json:"highContrast"`
+	RuneMode          string  `json:"runeMode"`
+}
+
+type edgeStatsJSON struct {
+	Directional      bool    `json:"directional"`
+	Threshold        float64 `json:"threshold"`
+	HighestMagnitude float64 `json:"highestMagnitude"`
+	EdgeCells        int     `json:"edgeCells"`
+}
+
+// MarshalJSON encodes the grid as one string per row and colors as #rrggbb strings.
+// Colors and Luminance are omitted when nil.
+func (r *RenderResult) MarshalJSON() ([]byte, error) {
+	out := renderResultJSON{
+		Source:     r.Source,
+		Cols:       r.Cols,
+		Rows:       r.Rows,
+		CellWidth:  r.CellWidth,
+		CellHeight: r.CellHeight,
+		Options: renderOptionsJSON{
+			TextSize:          r.Options.textSize,
+			FontAspect:        r.Options.fontAspect,
+			DirectionalRender: r.Options.directionalRender,
+			EdgeThreshold:     r.Options.edgeThreshold,
+			ReverseChars:      r.Options.reverseChars,
+			HighContrast:      r.Options.highContrast,
+			RuneMode:          r.Options.runeMode,
+		},
+		Edges: edgeStatsJSON{
+			Directional:      r.Edges.Directional,
+			Threshold:        r.Edges.Threshold,
+			HighestMagnitude: r.Edges.HighestMagnitude,
+			EdgeCells:        r.Edges.EdgeCells,
+		},
+		RenderedAt: r.RenderedAt,
+		DurationMs: float64(r.Duration.Microseconds()) / 1000,
+		Luminance:  r.Luminance,
+	}
+
+	out.Lines = make([]string, len(r.Grid))
+	for i, row := range r.Grid {
+		out.Lines[i] = string(row)
+	}
+
+	if r.Colors != nil {
+		out.Colors = make([][]string, len(r.Colors))
+		for i, row := range r.Colors {
+			out.Colors[i] = make([]string, len(row))
+			for j, c := range row {
+				out.Colors[i][j] = fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+			}
+		}
+	}
+
+	return json.Marshal(out)
+}
@@ -1,6 +1,8 @@
 package services_test
 
 import (
+	"encoding/json"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -138,6 +140,92 @@ func TestConvertImageToStringFileErrors(t *testing.T) {
 	})
 }
 
+func TestConvertReaderToStringMatchesFileConversion(t *testing.T) {
+	imagePath := ensureGeneratedFixture(t)
+	opts := mustRenderOptions(t, 8, 2.0, false, 0.6, false, false, "ASCII")
+
+	f, err := os.Open(imagePath)
+	if err != nil {
+		t.Fatalf("failed opening fixture: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	fromReader, err := services.ConvertReaderToString(f, opts)
+	if err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+	fromFile := mustConvertImageToString(t, imagePath, opts)
+
+	if services.ImageRuneArrayIntoString(fromReader) != fromFile {
+		t.Fatalf("expected reader and file conversions to match")
+	}
+}
+
+func TestRenderImageCarriesMetadata(t *testing.T) {
+	imagePath := ensureGeneratedFixture(t)
+	opts := mustRenderOptions(t, 8, 2.0, true, 0.4, false, false, "ASCII")
+
+	result, err := services.RenderImage(imagePath, opts)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if result.Source.Path != imagePath || result.Source.Format != "png" {
+		t.Fatalf("unexpected source info %+v", result.Source)
+	}
+	if result.Source.Width != 160 || result.Source.Height != 96 {
+		t.Fatalf("unexpected source size %dx%d", result.Source.Width, result.Source.Height)
+	}
+	if result.Cols != 20 || result.Rows != 6 || len(result.Grid) != result.Rows {
+		t.Fatalf("unexpected grid size %dx%d", result.Cols, result.Rows)
+	}
+	if len(result.Colors) != result.Rows || len(result.Luminance) != result.Rows {
+		t.Fatalf("expected per-cell colors and luminance")
+	}
+	if !result.Edges.Directional || result.Edges.EdgeCells == 0 {
+		t.Fatalf("expected edge statistics for directional render, got %+v", result.Edges)
+	}
+}
+
+func TestRenderResultMarshalJSON(t *testing.T) {
+	imagePath := ensureGeneratedFixture(t)
+	result, err := services.RenderImage(imagePath, mustRenderOptions(t, 8, 2.0, false, 0.6, false, false, "DOTS"))
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	result.Luminance = nil
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+
+	var decoded struct {
+		Cols      int             `json:"cols"`
+		Lines     []string        `json:"lines"`
+		Colors    [][]string      `json:"colors"`
+		Options   map[string]any  `json:"options"`
+		Luminance json.RawMessage `json:"luminance"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if decoded.Cols != result.Cols || len(decoded.Lines) != result.Rows {
+		t.Fatalf("unexpected decoded grid %d cols / %d lines", decoded.Cols, len(decoded.Lines))
+	}
+	if decoded.Lines[0] != string(result.Grid[0]) {
+		t.Fatalf("expected first line %q, got %q", string(result.Grid[0]), decoded.Lines[0])
+	}
+	if len(decoded.Colors) != result.Rows || len(decoded.Colors[0][0]) != len("#rrggbb") {
+		t.Fatalf("expected hex colors per cell")
+	}
+	if decoded.Options["runeMode"] != "DOTS" {
+		t.Fatalf("expected options in json, got %v", decoded.Options)
+	}
+	if decoded.Luminance != nil {
+		t.Fatalf("expected luminance to be omitted when nil")
+	}
+}
+
 func TestImageRuneArrayIntoStringAddsLineBreaks(t *testing.T) {
 	in := [][]rune{
 		[]rune("ab"),
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/pkg/ascii"
+)
+
+// VideoExtensions lists the containers Mezzotone accepts as video input.
+// Everything except .y4m requires ffmpeg on PATH.
+var VideoExtensions = []string{".mp4", ".mkv", ".webm", ".mov", ".avi", ".m4v", ".y4m"}
+
+// ErrFFmpegNotFound is returned when a video needs ffmpeg and it is not installed.
+var ErrFFmpegNotFound = errors.New("ffmpeg not found on PATH, install it or convert the video to .y4m")
+
+// Frame is a decoded video frame with its presentation time relative to the start of the stream.
+type Frame struct {
+	Image     image.Image
+	Timestamp time.Duration
+	Duration  time.Duration
+}
+
+// FrameSource yields frames in presentation order. Next returns io.EOF after the last frame.
+type FrameSource interface {
+	Next() (Frame, error)
+	Close() error
+}
+
+func IsVideoFile(path string) bool {
+	return slices.Contains(VideoExtensions, strings.ToLower(filepath.Ext(path)))
+}
+
+// OpenVideo opens path as a frame stream.
+// .y4m files are read with the pure-Go reader, any other container is decoded through ffmpeg.
+func OpenVideo(path string) (FrameSource, error) {
+	if strings.EqualFold(filepath.Ext(path), ".y4m") {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		reader, err := NewY4MReader(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		_ = Logger().Info(fmt.Sprintf("Opened y4m video: %s", path))
+		return reader, nil
+	}
+
+	return openFFmpegSource(path)
+}
+
+// ffmpegSource decodes any container ffmpeg understands into a YUV4MPEG2 pipe.
+type ffmpegSource struct {
+	cmd    *exec.Cmd
+	reader *Y4MReader
+	stderr lockedBuffer
+
+	// reading is held by Next while it reads the pipe. exec must not Wait before every read of
+	// StdoutPipe returned, so Close kills ffmpeg to end a blocked read and then takes it.
+	reading   sync.Mutex
+	closed    bool
+	closeOnce sync.Once
+}
+
+// lockedBuffer lets ffmpeg's stderr be read while exec is still copying into it.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func openFFmpegSource(path string) (*ffmpegSource, error) {
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil, ErrFFmpegNotFound
+	}
+
+	source := &ffmpegSource{}
+	source.cmd = exec.Command(
+		ffmpegPath,
+		"-hide_banner", "-loglevel", "error", "-nostdin",
+		"-i", path,
+		"-an",
+		"-f", "yuv4mpegpipe", "-pix_fmt", "yuv420p",
+		"-",
+	)
+	source.cmd.Stderr = &source.stderr
+
+	stdout, err := source.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := source.cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	source.reader, err = NewY4MReader(stdout)
+	if err != nil {
+		_ = source.Close()
+		return nil, source.wrapError(err)
+	}
+	_ = Logger().Info(fmt.Sprintf("Opened video through ffmpeg: %s", path))
+	return source, nil
+}
+
+func (s *ffmpegSource) Next() (Frame, error) {
+	s.reading.Lock()
+	defer s.reading.Unlock()
+	if s.closed {
+		return Frame{}, io.EOF
+	}
+
+	frame, err := s.reader.Next()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return Frame{}, s.wrapError(err)
+	}
+	return frame, err
+}
+
+// Close stops ffmpeg. It is safe to call while another goroutine is blocked in Next, which then
+// returns an error or io.EOF.
+func (s *ffmpegSource) Close() error {
+	s.closeOnce.Do(func() {
+		if s.cmd.Process != nil {
+			_ = s.cmd.Process.Kill()
+		}
+		s.reading.Lock()
+		s.closed = true
+		s.reading.Unlock()
+		_ = s.cmd.Wait()
+	})
+	return nil
+}
+
+// wrapError attaches whatever ffmpeg printed, which is far more useful than a short read.
+func (s *ffmpegSource) wrapError(err error) error {
+	if msg := strings.TrimSpace(s.stderr.String()); msg != "" {
+		return fmt.Errorf("ffmpeg: %s: %w", msg, err)
+	}
+	return err
+}
+
+// RenderFrame converts a single video frame with the given options.
+func RenderFrame(frame Frame, renderOptions RenderOptions) (*RenderResult, error) {
+	startedAt := time.Now()
+
+	converted, err := ascii.Convert(frame.Image, ascii.WithOptions(renderOptions.asciiOptions()))
+	if err != nil {
+		return nil, err
+	}
+
+	source := SourceInfo{Format: "video", Width: converted.SourceWidth, Height: converted.SourceHeight}
+	return newRenderResult(converted, renderOptions, source, startedAt), nil
+}
@@ -0,0 +1,118 @@
+package services_test
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
+	"codeberg.org/JoaoGarcia/Mezzotone/pkg/ascii"
+)
+
+func solidFrame(width, height int, c color.Color) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func writeY4MFixture(t *testing.T, frames []image.Image, fps int) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "clip.y4m")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed creating y4m fixture: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if err := services.EncodeY4M(f, frames, fps); err != nil {
+		t.Fatalf("failed encoding y4m fixture: %v", err)
+	}
+	return path
+}
+
+func TestY4MReaderDecodesFramesWithTimestamps(t *testing.T) {
+	frames := []image.Image{
+		solidFrame(16, 8, color.Black),
+		solidFrame(16, 8, color.White),
+		solidFrame(16, 8, color.Black),
+	}
+	var buf bytes.Buffer
+	if err := services.EncodeY4M(&buf, frames, 10); err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	reader, err := services.NewY4MReader(&buf)
+	if err != nil {
+		t.Fatalf("failed reading header: %v", err)
+	}
+	if w, h := reader.Size(); w != 16 || h != 8 {
+		t.Fatalf("unexpected size %dx%d", w, h)
+	}
+
+	for i := range frames {
+		frame, err := reader.Next()
+		if err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		if want := time.Duration(i) * 100 * time.Millisecond; frame.Timestamp != want {
+			t.Fatalf("frame %d: expected timestamp %v, got %v", i, want, frame.Timestamp)
+		}
+		r, _, _, _ := frame.Image.At(4, 4).RGBA()
+		if i == 1 && r>>8 < 250 {
+			t.Fatalf("expected white second frame, got red=%d", r>>8)
+		}
+	}
+
+	if _, err := reader.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected io.EOF after last frame, got %v", err)
+	}
+}
+
+func TestY4MReaderRejectsInvalidHeader(t *testing.T) {
+	if _, err := services.NewY4MReader(bytes.NewBufferString("NOTY4M W2 H2\n")); err == nil {
+		t.Fatalf("expected error for missing signature")
+	}
+	if _, err := services.NewY4MReader(bytes.NewBufferString("YUV4MPEG2 W2 H2 C444alpha\n")); err == nil {
+		t.Fatalf("expected error for unsupported color space")
+	}
+	for _, header := range []string{"YUV4MPEG2 W100000 H100000\n", "YUV4MPEG2 W9223372036854775807 H2\n"} {
+		if _, err := services.NewY4MReader(bytes.NewBufferString(header)); !errors.Is(err, ascii.ErrInputTooLarge) {
+			t.Fatalf("expected ErrInputTooLarge for %q, got %v", header, err)
+		}
+	}
+}
+
+func TestOpenVideoY4MFeedsRenderPipeline(t *testing.T) {
+	path := writeY4MFixture(t, []image.Image{solidFrame(32, 32, color.White)}, 25)
+	if !services.IsVideoFile(path) {
+		t.Fatalf("expected %s to be recognized as video", path)
+	}
+
+	source, err := services.OpenVideo(path)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	defer func() { _ = source.Close() }()
+
+	frame, err := source.Next()
+	if err != nil {
+		t.Fatalf("failed reading frame: %v", err)
+	}
+	result, err := services.RenderFrame(frame, mustRenderOptions(t, 8, 2.0, false, 0.6, false, false, "ASCII"))
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if result.Cols != 4 || result.Rows != 2 {
+		t.Fatalf("unexpected grid %dx%d", result.Cols, result.Rows)
+	}
+}
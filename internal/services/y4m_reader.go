@@ -0,0 +1,204 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/pkg/ascii"
+)
+
+const y4mSignature = "YUV4MPEG2"
+
+// Y4MReader decodes a YUV4MPEG2 stream frame by frame.
+// It is used directly for .y4m files and to read the ffmpeg pipe.
+type Y4MReader struct {
+	r      *bufio.Reader
+	closer io.Closer
+
+	width, height  int
+	frameRate      time.Duration
+	colorSpace     string
+	subsampleRatio image.YCbCrSubsampleRatio
+	monochrome     bool
+	frameIndex     int
+}
+
+// NewY4MReader parses the stream header. If r is an io.Closer it is closed by Close.
+func NewY4MReader(r io.Reader) (*Y4MReader, error) {
+	reader := &Y4MReader{
+		r:          bufio.NewReaderSize(r, 1<<16),
+		colorSpace: "420jpeg",
+	}
+	if c, ok := r.(io.Closer); ok {
+		reader.closer = c
+	}
+
+	header, err := reader.r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("y4m: reading header: %w", err)
+	}
+	fields := strings.Fields(header)
+	if len(fields) == 0 || fields[0] != y4mSignature {
+		return nil, errors.New("y4m: missing YUV4MPEG2 signature")
+	}
+
+	frameNum, frameDen := 25, 1
+	for _, field := range fields[1:] {
+		value := field[1:]
+		switch field[0] {
+		case 'W':
+			reader.width, err = strconv.Atoi(value)
+		case 'H':
+			reader.height, err = strconv.Atoi(value)
+		case 'F':
+			frameNum, frameDen, err = parseY4MRatio(value)
+		case 'C':
+			reader.colorSpace = value
+		}
+		if err != nil {
+			return nil, fmt.Errorf("y4m: invalid header field %q: %w", field, err)
+		}
+	}
+	if reader.width <= 0 || reader.height <= 0 {
+		return nil, fmt.Errorf("y4m: invalid frame size %dx%d", reader.width, reader.height)
+	}
+	// Every frame is allocated at the size the header claims, so it is held to the limit of
+	// decoded images. Divided rather than multiplied so a huge size cannot overflow.
+	if reader.width > ascii.DefaultMaxPixels/reader.height {
+		return nil, fmt.Errorf("%w: y4m frames of %dx%d exceed %d pixels",
+			ascii.ErrInputTooLarge, reader.width, reader.height, ascii.DefaultMaxPixels)
+	}
+	if frameNum <= 0 || frameDen <= 0 {
+		frameNum, frameDen = 25, 1
+	}
+	reader.frameRate = time.Duration(float64(time.Second) * float64(frameDen) / float64(frameNum))
+
+	// Only 8-bit planar layouts are supported; high bit depth and alpha variants are rejected.
+	switch reader.colorSpace {
+	case "420", "420jpeg", "420paldv", "420mpeg2":
+		reader.subsampleRatio = image.YCbCrSubsampleRatio420
+	case "422":
+		reader.subsampleRatio = image.YCbCrSubsampleRatio422
+	case "444":
+		reader.subsampleRatio = image.YCbCrSubsampleRatio444
+	case "mono":
+		reader.monochrome = true
+	default:
+		return nil, fmt.Errorf("y4m: unsupported color space %s", reader.colorSpace)
+	}
+
+	return reader, nil
+}
+
+func parseY4MRatio(value string) (int, int, error) {
+	num, den, ok := strings.Cut(value, ":")
+	if !ok {
+		return 0, 0, errors.New("expected num:den")
+	}
+	n, err := strconv.Atoi(num)
+	if err != nil {
+		return 0, 0, err
+	}
+	d, err := strconv.Atoi(den)
+	if err != nil {
+		return 0, 0, err
+	}
+	return n, d, nil
+}
+
+// Size returns the frame dimensions in pixels.
+func (y *Y4MReader) Size() (int, int) {
+	return y.width, y.height
+}
+
+// FrameDuration is the display time of one frame derived from the F header field.
+func (y *Y4MReader) FrameDuration() time.Duration {
+	return y.frameRate
+}
+
+// Next reads the next frame. It returns io.EOF once the stream ends cleanly.
+func (y *Y4MReader) Next() (Frame, error) {
+	line, err := y.r.ReadString('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) && line == "" {
+			return Frame{}, io.EOF
+		}
+		return Frame{}, fmt.Errorf("y4m: reading frame header: %w", err)
+	}
+	if !strings.HasPrefix(line, "FRAME") {
+		return Frame{}, fmt.Errorf("y4m: unexpected frame header %q", strings.TrimSpace(line))
+	}
+
+	var img image.Image
+	if y.monochrome {
+		gray := image.NewGray(image.Rect(0, 0, y.width, y.height))
+		if _, err := io.ReadFull(y.r, gray.Pix); err != nil {
+			return Frame{}, fmt.Errorf("y4m: reading frame %d: %w", y.frameIndex, err)
+		}
+		img = gray
+	} else {
+		ycbcr := image.NewYCbCr(image.Rect(0, 0, y.width, y.height), y.subsampleRatio)
+		for _, plane := range [][]byte{ycbcr.Y, ycbcr.Cb, ycbcr.Cr} {
+			if _, err := io.ReadFull(y.r, plane); err != nil {
+				return Frame{}, fmt.Errorf("y4m: reading frame %d: %w", y.frameIndex, err)
+			}
+		}
+		img = ycbcr
+	}
+
+	frame := Frame{
+		Image:     img,
+		Timestamp: time.Duration(y.frameIndex) * y.frameRate,
+		Duration:  y.frameRate,
+	}
+	y.frameIndex++
+	return frame, nil
+}
+
+func (y *Y4MReader) Close() error {
+	if y.closer == nil {
+		return nil
+	}
+	return y.closer.Close()
+}
+
+// EncodeY4M writes frames as a 4:4:4 YUV4MPEG2 stream. It is mainly useful for fixtures.
+func EncodeY4M(w io.Writer, frames []image.Image, fps int) error {
+	if len(frames) == 0 {
+		return errors.New("y4m: no frames to encode")
+	}
+	bounds := frames[0].Bounds()
+	if _, err := fmt.Fprintf(w, "%s W%d H%d F%d:1 Ip A1:1 C444\n", y4mSignature, bounds.Dx(), bounds.Dy(), fps); err != nil {
+		return err
+	}
+
+	for _, frame := range frames {
+		var planes [3]bytes.Buffer
+		for py := bounds.Min.Y; py < bounds.Max.Y; py++ {
+			for px := bounds.Min.X; px < bounds.Max.X; px++ {
+				r, g, b, _ := frame.At(px, py).RGBA()
+				yy, cb, cr := color.RGBToYCbCr(uint8(r>>8), uint8(g>>8), uint8(b>>8))
+				planes[0].WriteByte(yy)
+				planes[1].WriteByte(cb)
+				planes[2].WriteByte(cr)
+			}
+		}
+		if _, err := io.WriteString(w, "FRAME\n"); err != nil {
+			return err
+		}
+		for i := range planes {
+			if _, err := w.Write(planes[i].Bytes()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
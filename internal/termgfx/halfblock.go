@@ -0,0 +1,37 @@
+package termgfx
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+// RenderHalfBlocks draws img as cols x rows cells of '▀', whose foreground is the upper pixel
+// and background the lower one, so each cell shows two pixels in 24-bit color.
+func RenderHalfBlocks(img image.Image, cols, rows int) string {
+	if cols <= 0 || rows <= 0 {
+		return ""
+	}
+	scaled := resize(img, cols, rows*2)
+
+	var b strings.Builder
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			top := opaque(scaled.RGBAAt(col, row*2))
+			bottom := opaque(scaled.RGBAAt(col, row*2+1))
+			fmt.Fprintf(&b, "\x1b[38;2;%d;%d;%d;48;2;%d;%d;%dm▀", top.R, top.G, top.B, bottom.R, bottom.G, bottom.B)
+		}
+		b.WriteString("\x1b[0m")
+		if row < rows-1 {
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// opaque flattens a premultiplied pixel onto black.
+func opaque(c color.RGBA) color.RGBA {
+	c.A = 255
+	return c
+}
@@ -0,0 +1,41 @@
+package termgfx
+
+import (
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// FitCells returns the largest cell box inside maxCols x maxRows that keeps the aspect ratio of
+// a width x height image, given the pixel size of a cell.
+func FitCells(width, height, maxCols, maxRows, cellWidth, cellHeight int) (cols, rows int) {
+	if width <= 0 || height <= 0 || maxCols <= 0 || maxRows <= 0 || cellWidth <= 0 || cellHeight <= 0 {
+		return 0, 0
+	}
+	cols = maxCols
+	rows = height * cols * cellWidth / (width * cellHeight)
+	if rows > maxRows {
+		rows = maxRows
+		cols = width * rows * cellHeight / (height * cellWidth)
+	}
+	return max(1, cols), max(1, rows)
+}
+
+// Thumbnail scales img down so it fits in maxWidth x maxHeight pixels, never scaling up.
+func Thumbnail(img image.Image, maxWidth, maxHeight int) image.Image {
+	b := img.Bounds()
+	if b.Dx() <= maxWidth && b.Dy() <= maxHeight {
+		return img
+	}
+	w, h := maxWidth, b.Dy()*maxWidth/b.Dx()
+	if h > maxHeight {
+		w, h = b.Dx()*maxHeight/b.Dy(), maxHeight
+	}
+	return resize(img, max(1, w), max(1, h))
+}
+
+func resize(img image.Image, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+	return dst
+}
@@ -0,0 +1,43 @@
+package termgfx
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+)
+
+// KittyDeleteAll removes every image placed through the Kitty protocol and frees its data.
+const KittyDeleteAll = "\x1b_Ga=d,d=A,q=2\x1b\\"
+
+// kittyChunkSize is the largest base64 payload the protocol allows per escape sequence.
+const kittyChunkSize = 4096
+
+// EncodeKitty transmits img as PNG and displays it scaled to cols x rows cells at the cursor.
+// The cursor is not moved, so the surrounding text layout is unaffected.
+func EncodeKitty(img image.Image, cols, rows int) (string, error) {
+	var pngData bytes.Buffer
+	if err := png.Encode(&pngData, img); err != nil {
+		return "", err
+	}
+	payload := base64.StdEncoding.EncodeToString(pngData.Bytes())
+
+	var b strings.Builder
+	for first := true; first || payload != ""; first = false {
+		chunk := payload[:min(kittyChunkSize, len(payload))]
+		payload = payload[len(chunk):]
+
+		more := 0
+		if payload != "" {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(&b, "\x1b_Ga=T,f=100,q=2,C=1,c=%d,r=%d,m=%d;%s\x1b\\", cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return b.String(), nil
+}
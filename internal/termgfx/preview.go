@@ -0,0 +1,57 @@
+package termgfx
+
+import (
+	"image"
+	"strings"
+)
+
+// maxSixelPixels bounds the thumbnail sent as Sixel, which the terminal cannot scale itself.
+const maxSixelPixels = 640
+
+// Preview is an image prepared to occupy a Cols x Rows cell box.
+// Text holds what to print in the box: colored half blocks, or blank cells that the escape
+// sequence in Graphics draws over once the cursor is placed at the top left of the box.
+type Preview struct {
+	Cols, Rows int
+	Text       string
+	Graphics   string
+}
+
+// NewPreview fits img into maxCols x maxRows cells using the best protocol in caps.
+func NewPreview(caps Capabilities, img image.Image, maxCols, maxRows int) (Preview, error) {
+	cellW, cellH := caps.CellWidth, caps.CellHeight
+	if cellW <= 0 || cellH <= 0 {
+		cellW, cellH = defaultCellWidth, defaultCellHeight
+	}
+	b := img.Bounds()
+
+	switch caps.Protocol {
+	case ProtocolKitty:
+		cols, rows := FitCells(b.Dx(), b.Dy(), maxCols, maxRows, cellW, cellH)
+		graphics, err := EncodeKitty(Thumbnail(img, cols*cellW, rows*cellH), cols, rows)
+		if err != nil {
+			return Preview{}, err
+		}
+		return Preview{Cols: cols, Rows: rows, Text: blankCells(cols, rows), Graphics: graphics}, nil
+
+	case ProtocolSixel:
+		maxCols = min(maxCols, maxSixelPixels/cellW)
+		maxRows = min(maxRows, maxSixelPixels/cellH)
+		cols, rows := FitCells(b.Dx(), b.Dy(), maxCols, maxRows, cellW, cellH)
+		thumb := resize(img, cols*cellW, rows*cellH)
+		return Preview{Cols: cols, Rows: rows, Text: blankCells(cols, rows), Graphics: EncodeSixel(thumb)}, nil
+
+	case ProtocolHalfBlocks:
+		cols, rows := FitCells(b.Dx(), b.Dy(), maxCols, maxRows, cellW, cellH)
+		return Preview{Cols: cols, Rows: rows, Text: RenderHalfBlocks(img, cols, rows)}, nil
+	}
+	return Preview{}, nil
+}
+
+func blankCells(cols, rows int) string {
+	if cols <= 0 || rows <= 0 {
+		return ""
+	}
+	line := strings.Repeat(" ", cols)
+	return strings.TrimSuffix(strings.Repeat(line+"\n", rows), "\n")
+}
@@ -0,0 +1,103 @@
+package termgfx
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+// sixelLevels is the number of steps per channel of the fixed 6x6x6 color cube.
+const sixelLevels = 6
+
+// EncodeSixel encodes img as a Sixel image. Colors are quantized onto a 216 color cube, which
+// keeps encoding a single pass and is plenty for a preview thumbnail.
+func EncodeSixel(img image.Image) string {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width <= 0 || height <= 0 {
+		return ""
+	}
+
+	indices := make([]int, width*height)
+	used := make([]bool, sixelLevels*sixelLevels*sixelLevels)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			r, g, bl, _ := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			index := cubeLevel(r)*sixelLevels*sixelLevels + cubeLevel(g)*sixelLevels + cubeLevel(bl)
+			indices[y*width+x] = index
+			used[index] = true
+		}
+	}
+
+	var out strings.Builder
+	// Pixel aspect 1:1, background left as is, raster size declared up front.
+	fmt.Fprintf(&out, "\x1bP0;1;0q\"1;1;%d;%d", width, height)
+	for index, ok := range used {
+		if !ok {
+			continue
+		}
+		r, g, bl := index/(sixelLevels*sixelLevels), index/sixelLevels%sixelLevels, index%sixelLevels
+		fmt.Fprintf(&out, "#%d;2;%d;%d;%d", index, cubePercent(r), cubePercent(g), cubePercent(bl))
+	}
+
+	row := make([]byte, width)
+	for top := 0; top < height; top += 6 {
+		first := true
+		for index, ok := range used {
+			if !ok || !bandUses(indices, width, height, top, index) {
+				continue
+			}
+			for x := 0; x < width; x++ {
+				var bits byte
+				for dy := 0; dy < 6 && top+dy < height; dy++ {
+					if indices[(top+dy)*width+x] == index {
+						bits |= 1 << dy
+					}
+				}
+				row[x] = '?' + bits
+			}
+			if !first {
+				out.WriteByte('$')
+			}
+			first = false
+			fmt.Fprintf(&out, "#%d", index)
+			writeSixelRun(&out, row)
+		}
+		out.WriteByte('-')
+	}
+	out.WriteString("\x1b\\")
+	return out.String()
+}
+
+func bandUses(indices []int, width, height, top, index int) bool {
+	for i := top * width; i < min(top+6, height)*width; i++ {
+		if indices[i] == index {
+			return true
+		}
+	}
+	return false
+}
+
+// writeSixelRun writes row with repeats of four or more collapsed into !<count><char>.
+func writeSixelRun(out *strings.Builder, row []byte) {
+	for i := 0; i < len(row); {
+		j := i
+		for j < len(row) && row[j] == row[i] {
+			j++
+		}
+		if n := j - i; n >= 4 {
+			fmt.Fprintf(out, "!%d%c", n, row[i])
+		} else {
+			out.Write(row[i:j])
+		}
+		i = j
+	}
+}
+
+func cubeLevel(v uint32) int {
+	return int((v>>8)*(sixelLevels-1)+127) / 255
+}
+
+func cubePercent(level int) int {
+	return level * 100 / (sixelLevels - 1)
+}
@@ -0,0 +1,184 @@
+// Package termgfx draws images inside the terminal using the Kitty graphics protocol or Sixel
+// when the terminal supports them, and colored half-block characters otherwise.
+package termgfx
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/muesli/cancelreader"
+)
+
+type Protocol int
+
+const (
+	// ProtocolNone disables image previews.
+	ProtocolNone Protocol = iota
+	ProtocolHalfBlocks
+	ProtocolSixel
+	ProtocolKitty
+)
+
+func (p Protocol) String() string {
+	switch p {
+	case ProtocolHalfBlocks:
+		return "half blocks"
+	case ProtocolSixel:
+		return "sixel"
+	case ProtocolKitty:
+		return "kitty"
+	default:
+		return "none"
+	}
+}
+
+// Capabilities is what the terminal reported about its graphics support.
+type Capabilities struct {
+	Protocol Protocol
+	// CellWidth and CellHeight are the pixel size of one character cell.
+	CellWidth, CellHeight int
+}
+
+// Fallback cell size when the terminal does not report one.
+const (
+	defaultCellWidth  = 10
+	defaultCellHeight = 20
+)
+
+// DefaultCapabilities is the safe choice for terminals that were not queried.
+func DefaultCapabilities() Capabilities {
+	return Capabilities{Protocol: ProtocolHalfBlocks, CellWidth: defaultCellWidth, CellHeight: defaultCellHeight}
+}
+
+// EnvOverride names the environment variable that forces a protocol: kitty, sixel, blocks or none.
+const EnvOverride = "MEZZOTONE_GRAPHICS"
+
+// QuerySequence asks for Kitty graphics support, the cell size in pixels and the primary
+// device attributes. Every terminal answers the device attributes request, so its reply marks
+// the end of the responses.
+const QuerySequence = "\x1b_Gi=31,s=1,v=1,a=q,t=d,f=24;AAAA\x1b\\" + "\x1b[16t" + "\x1b[c"
+
+var (
+	kittyReply    = regexp.MustCompile(`\x1b_Gi=31;OK\x1b\\`)
+	cellSizeReply = regexp.MustCompile(`\x1b\[6;(\d+);(\d+)t`)
+	deviceReply   = regexp.MustCompile(`\x1b\[\?([\d;]*)c`)
+)
+
+// ParseResponses interprets the terminal's replies to QuerySequence.
+// Kitty wins over Sixel because it scales images itself and survives redraws of the text layer.
+func ParseResponses(replies []byte) Capabilities {
+	caps := DefaultCapabilities()
+
+	if m := cellSizeReply.FindSubmatch(replies); m != nil {
+		h, _ := strconv.Atoi(string(m[1]))
+		w, _ := strconv.Atoi(string(m[2]))
+		if w > 0 && h > 0 {
+			caps.CellWidth, caps.CellHeight = w, h
+		}
+	}
+
+	switch {
+	case kittyReply.Match(replies):
+		caps.Protocol = ProtocolKitty
+	case deviceAttributesInclude(replies, "4"):
+		caps.Protocol = ProtocolSixel
+	}
+	return caps
+}
+
+func deviceAttributesInclude(replies []byte, attribute string) bool {
+	m := deviceReply.FindSubmatch(replies)
+	if m == nil {
+		return false
+	}
+	for _, attr := range strings.Split(string(m[1]), ";") {
+		if attr == attribute {
+			return true
+		}
+	}
+	return false
+}
+
+// ProtocolFromEnv returns the protocol forced through EnvOverride, if any.
+func ProtocolFromEnv(getenv func(string) string) (Protocol, bool) {
+	switch strings.ToLower(getenv(EnvOverride)) {
+	case "kitty":
+		return ProtocolKitty, true
+	case "sixel":
+		return ProtocolSixel, true
+	case "blocks", "halfblocks":
+		return ProtocolHalfBlocks, true
+	case "none", "off":
+		return ProtocolNone, true
+	}
+	return ProtocolNone, false
+}
+
+// Detect queries the terminal on in/out and returns its capabilities. It must run before the
+// TUI takes over stdin. Terminals that do not answer within timeout get half blocks.
+func Detect(in, out *os.File, timeout time.Duration) Capabilities {
+	caps := DefaultCapabilities()
+	forced, isForced := ProtocolFromEnv(os.Getenv)
+	if isForced && forced == ProtocolNone {
+		// Every protocol sizes the preview from the cell size, so only turning previews off
+		// can skip the query.
+		caps.Protocol = forced
+		return caps
+	}
+	if !term.IsTerminal(in.Fd()) || !term.IsTerminal(out.Fd()) {
+		if isForced {
+			caps.Protocol = forced
+		}
+		return caps
+	}
+
+	replies, err := query(in, out, timeout)
+	if err == nil {
+		caps = ParseResponses(replies)
+	}
+	if isForced {
+		caps.Protocol = forced
+	}
+	return caps
+}
+
+func query(in, out *os.File, timeout time.Duration) ([]byte, error) {
+	state, err := term.MakeRaw(in.Fd())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = term.Restore(in.Fd(), state) }()
+
+	reader, err := cancelreader.NewReader(in)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = reader.Close() }()
+
+	if _, err := out.WriteString(QuerySequence); err != nil {
+		return nil, err
+	}
+
+	// The reader is cancelled on timeout, so no goroutine is left behind stealing input.
+	timer := time.AfterFunc(timeout, func() { reader.Cancel() })
+	defer timer.Stop()
+
+	var replies []byte
+	buf := make([]byte, 256)
+	for !deviceReply.Match(replies) {
+		n, err := reader.Read(buf)
+		replies = append(replies, buf[:n]...)
+		if err != nil {
+			if errors.Is(err, cancelreader.ErrCanceled) {
+				return replies, nil
+			}
+			return replies, err
+		}
+	}
+	return replies, nil
+}
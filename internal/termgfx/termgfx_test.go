@@ -0,0 +1,115 @@
+package termgfx_test
+
+import (
+	"image"
+	"image/color"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/termgfx"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func solidImage(w, h int, c color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestParseResponsesPicksProtocolAndCellSize(t *testing.T) {
+	cases := []struct {
+		name    string
+		replies string
+		want    termgfx.Protocol
+	}{
+		{"kitty", "\x1b_Gi=31;OK\x1b\\\x1b[6;20;10t\x1b[?62;22c", termgfx.ProtocolKitty},
+		{"sixel", "\x1b[6;18;9t\x1b[?62;4;22c", termgfx.ProtocolSixel},
+		{"plain", "\x1b[?1;2c", termgfx.ProtocolHalfBlocks},
+		{"sixel attribute must match exactly", "\x1b[?62;42c", termgfx.ProtocolHalfBlocks},
+	}
+	for _, tc := range cases {
+		if got := termgfx.ParseResponses([]byte(tc.replies)).Protocol; got != tc.want {
+			t.Fatalf("%s: expected %s, got %s", tc.name, tc.want, got)
+		}
+	}
+
+	caps := termgfx.ParseResponses([]byte("\x1b[6;18;9t\x1b[?62;4c"))
+	if caps.CellWidth != 9 || caps.CellHeight != 18 {
+		t.Fatalf("expected 9x18 cells, got %dx%d", caps.CellWidth, caps.CellHeight)
+	}
+}
+
+func TestProtocolFromEnv(t *testing.T) {
+	env := map[string]string{termgfx.EnvOverride: "SIXEL"}
+	if p, ok := termgfx.ProtocolFromEnv(func(k string) string { return env[k] }); !ok || p != termgfx.ProtocolSixel {
+		t.Fatalf("expected forced sixel, got %s %v", p, ok)
+	}
+	if _, ok := termgfx.ProtocolFromEnv(func(string) string { return "" }); ok {
+		t.Fatalf("expected no override without the variable")
+	}
+}
+
+func TestFitCellsKeepsAspectRatio(t *testing.T) {
+	// A square image on 10x20 cells is twice as many columns as rows.
+	if cols, rows := termgfx.FitCells(400, 400, 80, 20, 10, 20); cols != 40 || rows != 20 {
+		t.Fatalf("expected 40x20 cells, got %dx%d", cols, rows)
+	}
+	if cols, rows := termgfx.FitCells(800, 100, 40, 40, 10, 20); cols != 40 || rows != 2 {
+		t.Fatalf("expected 40x2 cells, got %dx%d", cols, rows)
+	}
+}
+
+func TestRenderHalfBlocksFillsBox(t *testing.T) {
+	out := termgfx.RenderHalfBlocks(solidImage(8, 8, color.RGBA{R: 255, A: 255}), 4, 2)
+	lines := strings.Split(out, "\n")
+	if len(lines) != 2 || ansi.StringWidth(lines[0]) != 4 {
+		t.Fatalf("expected 2 lines of 4 cells, got %q", out)
+	}
+	if !strings.Contains(out, "\x1b[38;2;255;0;0;48;2;255;0;0m▀") {
+		t.Fatalf("expected red half blocks, got %q", out)
+	}
+}
+
+func TestEncodeSixelUsesRasterAttributesAndRepeats(t *testing.T) {
+	out := termgfx.EncodeSixel(solidImage(10, 6, color.RGBA{R: 255, A: 255}))
+	if !strings.HasPrefix(out, "\x1bP0;1;0q\"1;1;10;6") || !strings.HasSuffix(out, "\x1b\\") {
+		t.Fatalf("unexpected sixel framing %q", out)
+	}
+	// Pure red is cube index 5*36 = 180 at 100% red.
+	if !strings.Contains(out, "#180;2;100;0;0") || !strings.Contains(out, "#180!10~-") {
+		t.Fatalf("expected one red band written as a repeat, got %q", out)
+	}
+}
+
+func TestEncodeKittyChunksPayload(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	noise := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for i := range noise.Pix {
+		noise.Pix[i] = byte(rng.UintN(256))
+	}
+	out, err := termgfx.EncodeKitty(noise, 6, 3)
+	if err != nil {
+		t.Fatalf("kitty encode failed: %v", err)
+	}
+	if !strings.HasPrefix(out, "\x1b_Ga=T,f=100,q=2,C=1,c=6,r=3,m=1;") {
+		t.Fatalf("unexpected first chunk %q", out[:min(len(out), 60)])
+	}
+	if !strings.Contains(out, "\x1b_Gm=0;") || strings.Count(out, "\x1b_G") < 2 {
+		t.Fatalf("expected the payload split over several chunks ending with m=0")
+	}
+}
+
+func TestNewPreviewHalfBlocksHasNoGraphics(t *testing.T) {
+	preview, err := termgfx.NewPreview(termgfx.DefaultCapabilities(), solidImage(100, 100, color.White), 30, 10)
+	if err != nil {
+		t.Fatalf("preview failed: %v", err)
+	}
+	if preview.Graphics != "" || preview.Rows != 10 || preview.Cols != 20 {
+		t.Fatalf("unexpected half block preview %dx%d graphics %d bytes", preview.Cols, preview.Rows, len(preview.Graphics))
+	}
+}
@@ -0,0 +1,271 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ExportFormat is one selectable output format and the file extension it implies.
+type ExportFormat struct {
+	Name      string
+	Extension string
+}
+
+// ExportRequestMsg is emitted when the user confirms the dialog. Overwrite is never set by the
+// dialog, the owner sets it once the user agreed to replace an existing file.
+type ExportRequestMsg struct {
+	Path      string
+	Format    string
+	Overwrite bool
+}
+
+// ExportDialogKeyMap is the keys of an ExportDialog. Next and Prev switch between the path
+// and the format, PrevFormat and NextFormat step the format while it has the focus.
+type ExportDialogKeyMap struct {
+	Next, Prev, PrevFormat, NextFormat, Save, Cancel key.Binding
+}
+
+func DefaultExportDialogKeyMap() ExportDialogKeyMap {
+	return ExportDialogKeyMap{
+		Next:       key.NewBinding(key.WithKeys("tab", "down"), key.WithHelp("tab", "switch field")),
+		Prev:       key.NewBinding(key.WithKeys("shift+tab", "up"), key.WithHelp("shift+tab", "switch field")),
+		PrevFormat: key.NewBinding(key.WithKeys("left", "h"), key.WithHelp("←", "format")),
+		NextFormat: key.NewBinding(key.WithKeys("right", "l", " "), key.WithHelp("→", "format")),
+		Save:       key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "save")),
+		Cancel:     key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
+	}
+}
+
+const (
+	exportFocusPath = iota
+	exportFocusFormat
+)
+
+type ExportDialog struct {
+	Visible bool
+	KeyMap  ExportDialogKeyMap
+	Theme   Theme
+
+	formats     []ExportFormat
+	formatIndex int
+	focus       int
+
+	errMsg string
+
+	input textinput.Model
+	width int
+}
+
+func NewExportDialog(formats []ExportFormat) ExportDialog {
+	ti := textinput.New()
+	ti.Prompt = ""
+	ti.CharLimit = 4096
+
+	return ExportDialog{
+		KeyMap:  DefaultExportDialogKeyMap(),
+		Theme:   DefaultTheme(),
+		formats: formats,
+		input:   ti,
+	}
+}
+
+// Open shows the dialog with defaultPath, switching its extension to the selected format.
+func (d *ExportDialog) Open(defaultPath string) {
+	d.Visible = true
+	d.focus = exportFocusPath
+	d.errMsg = ""
+	d.input.SetValue(d.withFormatExtension(defaultPath))
+	d.input.CursorEnd()
+	d.input.Focus()
+}
+
+func (d *ExportDialog) Close() {
+	d.Visible = false
+	d.errMsg = ""
+	d.input.Blur()
+}
+
+func (d *ExportDialog) SetError(msg string) {
+	d.errMsg = msg
+}
+
+// SetFormats replaces the selectable formats, keeping the current one when it is still offered.
+func (d *ExportDialog) SetFormats(formats []ExportFormat) {
+	current := d.Format()
+	d.formats = formats
+	d.formatIndex = 0
+	for i, f := range formats {
+		if f.Name == current {
+			d.formatIndex = i
+			break
+		}
+	}
+}
+
+func (d *ExportDialog) SetWidth(w int) {
+	d.width = w
+	d.input.Width = max(1, dialogInnerWidth(w)-1)
+}
+
+// dialogInnerWidth is the content width of a dialog box w wide.
+func dialogInnerWidth(w int) int {
+	return max(1, w-2-4 /*border + padding left+right*/)
+}
+
+func (d *ExportDialog) Path() string {
+	return strings.TrimSpace(d.input.Value())
+}
+
+func (d *ExportDialog) Format() string {
+	if len(d.formats) == 0 {
+		return ""
+	}
+	return d.formats[d.formatIndex].Name
+}
+
+func (d *ExportDialog) Update(msg tea.Msg) (ExportDialog, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !d.Visible {
+		return *d, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, d.KeyMap.Cancel):
+		d.Close()
+		return *d, nil
+
+	case key.Matches(keyMsg, d.KeyMap.Next, d.KeyMap.Prev):
+		d.errMsg = ""
+		if d.focus == exportFocusPath {
+			d.focus = exportFocusFormat
+			d.input.Blur()
+		} else {
+			d.focus = exportFocusPath
+			d.input.Focus()
+		}
+		return *d, nil
+
+	case key.Matches(keyMsg, d.KeyMap.Save):
+		if d.Path() == "" {
+			d.errMsg = "path must not be empty"
+			return *d, nil
+		}
+		d.errMsg = ""
+		return *d, d.request()
+	}
+
+	if d.focus == exportFocusFormat {
+		switch {
+		case key.Matches(keyMsg, d.KeyMap.PrevFormat):
+			d.stepFormat(-1)
+		case key.Matches(keyMsg, d.KeyMap.NextFormat):
+			d.stepFormat(+1)
+		}
+		return *d, nil
+	}
+
+	var cmd tea.Cmd
+	d.input, cmd = d.input.Update(msg)
+	return *d, cmd
+}
+
+func (d *ExportDialog) request() tea.Cmd {
+	req := ExportRequestMsg{Path: d.Path(), Format: d.Format()}
+	return func() tea.Msg { return req }
+}
+
+func (d *ExportDialog) stepFormat(dir int) {
+	if len(d.formats) == 0 {
+		return
+	}
+	d.formatIndex = (d.formatIndex + dir + len(d.formats)) % len(d.formats)
+	d.input.SetValue(d.withFormatExtension(d.input.Value()))
+	d.input.CursorEnd()
+}
+
+// withFormatExtension replaces a known format extension on path with the selected one.
+func (d *ExportDialog) withFormatExtension(path string) string {
+	if path == "" || len(d.formats) == 0 {
+		return path
+	}
+	ext := filepath.Ext(path)
+	for _, f := range d.formats {
+		if strings.EqualFold(ext, f.Extension) {
+			path = strings.TrimSuffix(path, ext)
+			break
+		}
+	}
+	return path + d.formats[d.formatIndex].Extension
+}
+
+func (d *ExportDialog) View() string {
+	t := d.Theme
+	box := t.BoxStyle().
+		Padding(1, 2).
+		Width(d.width)
+
+	title := lipgloss.NewStyle().Bold(true).Render("EXPORT RENDER")
+	selected := t.SelectedStyle()
+	faint := t.FaintStyle()
+
+	innerW := dialogInnerWidth(d.width)
+
+	pathLabel := "Path"
+	formatLabel := "Format"
+	if d.focus == exportFocusPath {
+		pathLabel = selected.Render(pathLabel)
+	} else {
+		formatLabel = selected.Render(formatLabel)
+	}
+
+	formatNames := make([]string, len(d.formats))
+	for i, f := range d.formats {
+		if i == d.formatIndex {
+			formatNames[i] = "[" + f.Name + "]"
+		} else {
+			formatNames[i] = faint.Render(f.Name)
+		}
+	}
+
+	lines := []string{
+		title,
+		"",
+		pathLabel,
+		d.input.View(),
+		"",
+		formatLabel,
+		lipgloss.NewStyle().Width(innerW).Render(strings.Join(formatNames, " ")),
+		"",
+	}
+
+	if d.errMsg != "" {
+		lines = append(lines, t.ErrorStyle().Render("⚠ "+d.errMsg))
+	} else {
+		lines = append(lines, faint.Render(d.hint()))
+	}
+
+	return box.Render(strings.Join(lines, "\n"))
+}
+
+// hint is the help line of the dialog, e.g. "tab switch field · ←/→ format · enter save".
+func (d *ExportDialog) hint() string {
+	k := d.KeyMap
+	parts := make([]string, 0, 4)
+	if k.Next.Enabled() {
+		parts = append(parts, k.Next.Help().Key+" "+k.Next.Help().Desc)
+	}
+	if k.PrevFormat.Enabled() && k.NextFormat.Enabled() {
+		parts = append(parts, k.PrevFormat.Help().Key+"/"+k.NextFormat.Help().Key+" "+k.NextFormat.Help().Desc)
+	}
+	for _, b := range []key.Binding{k.Save, k.Cancel} {
+		if b.Enabled() {
+			parts = append(parts, b.Help().Key+" "+b.Help().Desc)
+		}
+	}
+	return strings.Join(parts, " · ")
+}
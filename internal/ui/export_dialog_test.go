@@ -0,0 +1,73 @@
+package ui_test
+
+import (
+	"strings"
+	"testing"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/ui"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func newExportDialogForTests() ui.ExportDialog {
+	d := ui.NewExportDialog([]ui.ExportFormat{
+		{Name: "TEXT", Extension: ".txt"},
+		{Name: "PNG", Extension: ".png"},
+	})
+	d.SetWidth(40)
+	d.Open("/tmp/cat_mezzotone")
+	return d
+}
+
+func TestExportDialogFormatSwitchUpdatesExtension(t *testing.T) {
+	d := newExportDialogForTests()
+	if d.Path() != "/tmp/cat_mezzotone.txt" {
+		t.Fatalf("expected default extension, got %q", d.Path())
+	}
+
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyTab})
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyRight})
+
+	if d.Format() != "PNG" || d.Path() != "/tmp/cat_mezzotone.png" {
+		t.Fatalf("expected PNG with .png path, got %q %q", d.Format(), d.Path())
+	}
+}
+
+func TestExportDialogEnterRequestsWithoutOverwrite(t *testing.T) {
+	d := newExportDialogForTests()
+
+	d, cmd := d.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	req, ok := cmd().(ui.ExportRequestMsg)
+	if !ok || req.Overwrite || req.Path != "/tmp/cat_mezzotone.txt" {
+		t.Fatalf("expected non-overwriting request, got %+v", req)
+	}
+	if !d.Visible {
+		t.Fatal("the dialog stays open until the owner closes it")
+	}
+}
+
+func TestExportDialogFollowsItsKeyMap(t *testing.T) {
+	d := newExportDialogForTests()
+	d.KeyMap.Next = key.NewBinding(key.WithKeys("ctrl+n"), key.WithHelp("ctrl+n", "switch field"))
+	d.KeyMap.Save = key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("ctrl+s", "save"))
+	if !strings.Contains(d.View(), "ctrl+n switch field") || !strings.Contains(d.View(), "ctrl+s save") {
+		t.Fatalf("expected the hint to show the rebound keys, got %q", d.View())
+	}
+
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyTab})
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if d.Format() != "TEXT" {
+		t.Fatalf("expected tab to no longer leave the path, got format %q", d.Format())
+	}
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if d.Format() != "PNG" {
+		t.Fatalf("expected ctrl+n to move to the format, got %q", d.Format())
+	}
+	if _, cmd := d.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Fatalf("expected enter to no longer save")
+	}
+	if _, cmd := d.Update(tea.KeyMsg{Type: tea.KeyCtrlS}); cmd == nil {
+		t.Fatalf("expected ctrl+s to save")
+	}
+}
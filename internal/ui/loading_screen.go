@@ -4,7 +4,6 @@ import (
 	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
-	"github.com/charmbracelet/lipgloss"
 )
 
 type LoadingScreen struct {
@@ -19,9 +18,14 @@ func NewLoadingScreen(animation []string, fps int) LoadingScreen {
 		Frames: animation,
 		FPS:    time.Second / time.Duration(fps),
 	}
-	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	s.Style = DefaultTheme().AccentStyle()
 
 	return LoadingScreen{
 		Spinner: s,
 	}
 }
+
+// SetTheme colors the spinner with the accent of t.
+func (l *LoadingScreen) SetTheme(t Theme) {
+	l.Spinner.Style = t.AccentStyle()
+}
@@ -0,0 +1,290 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// PromptSubmitMsg is emitted when the user confirms a prompt. ID tells prompts apart.
+type PromptSubmitMsg struct {
+	ID    string
+	Value string
+}
+
+// ModalResultMsg is emitted when the user answers a confirmation, Confirmed telling which
+// way. ID tells confirmations apart.
+type ModalResultMsg struct {
+	ID        string
+	Confirmed bool
+}
+
+// ModalKeyMap is the keys of a Modal. Next and Prev move through the input and the buttons,
+// Left and Right only through the buttons; Yes and No answer a confirmation.
+type ModalKeyMap struct {
+	Next, Prev, Left, Right, Press, Cancel, Yes, No key.Binding
+}
+
+func DefaultModalKeyMap() ModalKeyMap {
+	return ModalKeyMap{
+		Next:   key.NewBinding(key.WithKeys("tab", "down"), key.WithHelp("tab", "move")),
+		Prev:   key.NewBinding(key.WithKeys("shift+tab", "up"), key.WithHelp("shift+tab", "move back")),
+		Left:   key.NewBinding(key.WithKeys("left", "h"), key.WithHelp("left", "previous button")),
+		Right:  key.NewBinding(key.WithKeys("right", "l"), key.WithHelp("right", "next button")),
+		Press:  key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select")),
+		Cancel: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
+		Yes:    key.NewBinding(key.WithKeys("y", "Y"), key.WithHelp("y", "yes")),
+		No:     key.NewBinding(key.WithKeys("n", "N"), key.WithHelp("n", "no")),
+	}
+}
+
+type modalKind int
+
+const (
+	modalConfirm modalKind = iota
+	modalPrompt
+	modalError
+)
+
+// Modal is a dialog that keeps the keyboard until it is answered: a confirmation, a single
+// line prompt or an error with optional details. tab and shift+tab move between its input
+// and buttons, enter presses the focused one and esc cancels, see ModalKeyMap. The owner sends
+// it every key while it is Visible.
+type Modal struct {
+	Visible bool
+	KeyMap  ModalKeyMap
+	Theme   Theme
+
+	kind    modalKind
+	id      string
+	title   string
+	body    string
+	details string
+	buttons []string
+
+	// focus is the focused button, or the input of a prompt at -1.
+	focus       int
+	showDetails bool
+	errMsg      string
+
+	input textinput.Model
+	width int
+}
+
+func NewModal() Modal {
+	ti := textinput.New()
+	ti.Prompt = ""
+	ti.CharLimit = 64
+
+	return Modal{KeyMap: DefaultModalKeyMap(), Theme: DefaultTheme(), input: ti}
+}
+
+// Confirm asks a yes or no question. confirmLabel names the button that agrees, which has
+// the focus; y and n answer too.
+func (d *Modal) Confirm(id, title, body, confirmLabel string) {
+	d.open(modalConfirm, id, title, body, confirmLabel, "Cancel")
+	d.focus = 0
+}
+
+// Prompt asks for a single line of text with value prefilled. The prompt stays open after
+// PromptSubmitMsg until the owner closes it, so it can show an error instead.
+func (d *Modal) Prompt(id, title, value string) {
+	d.open(modalPrompt, id, title, "", "OK", "Cancel")
+	d.focus = -1
+	d.input.SetValue(value)
+	d.input.CursorEnd()
+	d.input.Focus()
+}
+
+// Error shows message, with details behind a button when there are any.
+func (d *Modal) Error(title, message, details string) {
+	buttons := []string{"OK"}
+	if details != "" {
+		buttons = append(buttons, "Details")
+	}
+	d.open(modalError, "", title, message, buttons...)
+	d.details = details
+	d.focus = 0
+}
+
+func (d *Modal) open(kind modalKind, id, title, body string, buttons ...string) {
+	d.Visible = true
+	d.kind = kind
+	d.id = id
+	d.title = title
+	d.body = body
+	d.details = ""
+	d.buttons = buttons
+	d.showDetails = false
+	d.errMsg = ""
+	d.input.Blur()
+}
+
+func (d *Modal) Close() {
+	d.Visible = false
+	d.errMsg = ""
+	d.input.Blur()
+}
+
+func (d *Modal) SetError(msg string) {
+	d.errMsg = msg
+}
+
+func (d *Modal) SetWidth(w int) {
+	d.width = w
+	d.input.Width = max(1, dialogInnerWidth(w)-1)
+}
+
+// Value is the trimmed text of a prompt.
+func (d *Modal) Value() string {
+	return strings.TrimSpace(d.input.Value())
+}
+
+func (d *Modal) Update(msg tea.Msg) (Modal, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || !d.Visible {
+		return *d, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, d.KeyMap.Cancel):
+		return *d, d.cancel()
+	case key.Matches(keyMsg, d.KeyMap.Next):
+		d.moveFocus(+1)
+		return *d, nil
+	case key.Matches(keyMsg, d.KeyMap.Prev):
+		d.moveFocus(-1)
+		return *d, nil
+	case key.Matches(keyMsg, d.KeyMap.Press):
+		return *d, d.press()
+	}
+
+	if d.kind == modalPrompt && d.focus < 0 {
+		d.errMsg = ""
+		var cmd tea.Cmd
+		d.input, cmd = d.input.Update(msg)
+		return *d, cmd
+	}
+
+	switch {
+	case key.Matches(keyMsg, d.KeyMap.Left):
+		d.moveFocus(-1)
+	case key.Matches(keyMsg, d.KeyMap.Right):
+		d.moveFocus(+1)
+	case d.kind == modalConfirm && key.Matches(keyMsg, d.KeyMap.Yes):
+		return *d, d.answer(true)
+	case d.kind == modalConfirm && key.Matches(keyMsg, d.KeyMap.No):
+		return *d, d.answer(false)
+	}
+	return *d, nil
+}
+
+// moveFocus steps through the input, when there is one, and the buttons, wrapping around.
+func (d *Modal) moveFocus(dir int) {
+	first := 0
+	if d.kind == modalPrompt {
+		first = -1
+	}
+	n := len(d.buttons) - first
+	d.focus = (d.focus-first+dir+n)%n + first
+
+	if d.focus < 0 {
+		d.input.Focus()
+	} else {
+		d.input.Blur()
+	}
+}
+
+func (d *Modal) press() tea.Cmd {
+	switch d.kind {
+	case modalConfirm:
+		return d.answer(d.focus == 0)
+
+	case modalPrompt:
+		if d.focus > 0 {
+			d.Close()
+			return nil
+		}
+		if d.Value() == "" {
+			d.errMsg = "must not be empty"
+			return nil
+		}
+		d.errMsg = ""
+		submit := PromptSubmitMsg{ID: d.id, Value: d.Value()}
+		return func() tea.Msg { return submit }
+	}
+
+	if d.focus > 0 {
+		d.showDetails = !d.showDetails
+		return nil
+	}
+	d.Close()
+	return nil
+}
+
+func (d *Modal) cancel() tea.Cmd {
+	if d.kind == modalConfirm {
+		return d.answer(false)
+	}
+	d.Close()
+	return nil
+}
+
+func (d *Modal) answer(confirmed bool) tea.Cmd {
+	d.Close()
+	result := ModalResultMsg{ID: d.id, Confirmed: confirmed}
+	return func() tea.Msg { return result }
+}
+
+func (d *Modal) View() string {
+	t := d.Theme
+	box := t.BoxStyle().
+		Padding(1, 2).
+		Width(d.width)
+
+	innerW := dialogInnerWidth(d.width)
+	wrap := lipgloss.NewStyle().Width(innerW)
+
+	title := lipgloss.NewStyle().Bold(true)
+	if d.kind == modalError {
+		title = t.ErrorStyle().Bold(true)
+	}
+	lines := []string{title.Render(strings.ToUpper(d.title)), ""}
+
+	if d.body != "" {
+		lines = append(lines, wrap.Render(d.body), "")
+	}
+	if d.kind == modalPrompt {
+		lines = append(lines, d.input.View(), "")
+	}
+	if d.showDetails {
+		lines = append(lines, t.FaintStyle().Inherit(wrap).Render(d.details), "")
+	}
+	if d.errMsg != "" {
+		lines = append(lines, t.ErrorStyle().Render("⚠ "+d.errMsg), "")
+	}
+
+	buttons := make([]string, len(d.buttons))
+	for i, label := range d.buttons {
+		if d.kind == modalError && i > 0 && d.showDetails {
+			label = "Hide details"
+		}
+		button := "[ " + label + " ]"
+		if i == d.focus {
+			button = t.SelectedStyle().Render(button)
+		}
+		buttons[i] = button
+	}
+	hint := make([]string, 0, 3)
+	for _, b := range []key.Binding{d.KeyMap.Next, d.KeyMap.Press, d.KeyMap.Cancel} {
+		if b.Enabled() {
+			hint = append(hint, b.Help().Key+" "+b.Help().Desc)
+		}
+	}
+	lines = append(lines, strings.Join(buttons, " "), t.FaintStyle().Render(strings.Join(hint, " · ")))
+
+	return box.Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,144 @@
+package ui_test
+
+import (
+	"strings"
+	"testing"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/ui"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestModalPromptSubmitsTrimmedValue(t *testing.T) {
+	d := ui.NewModal()
+	d.SetWidth(40)
+	d.Prompt("name", "Preset name", "Fine ")
+
+	d, cmd := d.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	msg, ok := cmd().(ui.PromptSubmitMsg)
+	if !ok || msg.ID != "name" || msg.Value != "Fine" {
+		t.Fatalf("unexpected submit %+v", msg)
+	}
+	if !d.Visible {
+		t.Fatal("the prompt stays open until the owner closes it")
+	}
+}
+
+func TestModalPromptRejectsEmptyValueAndCancels(t *testing.T) {
+	d := ui.NewModal()
+	d.Prompt("name", "Preset name", "")
+
+	d, cmd := d.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil {
+		t.Fatal("an empty value must not be submitted")
+	}
+
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if d.Visible {
+		t.Fatal("esc should close the prompt")
+	}
+}
+
+func TestModalPromptTabsToTheButtons(t *testing.T) {
+	d := ui.NewModal()
+	d.Prompt("name", "Preset name", "Fine")
+
+	// Letters go to the input while it has the focus, not to the buttons.
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if d.Value() != "Finen" {
+		t.Fatalf("expected the input to take the key, got %q", d.Value())
+	}
+
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	d, cmd := d.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || d.Visible {
+		t.Fatal("expected the Cancel button to close the prompt without submitting")
+	}
+}
+
+func TestModalConfirmAnswersWithButtonsAndKeys(t *testing.T) {
+	answer := func(d ui.Modal, keys ...tea.KeyMsg) (ui.Modal, ui.ModalResultMsg) {
+		t.Helper()
+		var cmd tea.Cmd
+		for _, k := range keys {
+			d, cmd = d.Update(k)
+		}
+		if cmd == nil {
+			t.Fatal("expected an answer")
+		}
+		return d, cmd().(ui.ModalResultMsg)
+	}
+	open := func() ui.Modal {
+		d := ui.NewModal()
+		d.SetWidth(40)
+		d.Confirm("quit", "Quit?", "Leave Mezzotone", "Quit")
+		return d
+	}
+
+	d, result := answer(open(), tea.KeyMsg{Type: tea.KeyEnter})
+	if !result.Confirmed || result.ID != "quit" || d.Visible {
+		t.Fatalf("expected enter to confirm and close, got %+v", result)
+	}
+	_, result = answer(open(), tea.KeyMsg{Type: tea.KeyTab}, tea.KeyMsg{Type: tea.KeyEnter})
+	if result.Confirmed {
+		t.Fatal("expected Cancel to decline")
+	}
+	// The focus wraps around the two buttons.
+	_, result = answer(open(), tea.KeyMsg{Type: tea.KeyRight}, tea.KeyMsg{Type: tea.KeyRight}, tea.KeyMsg{Type: tea.KeyEnter})
+	if !result.Confirmed {
+		t.Fatal("expected the focus to wrap back to Quit")
+	}
+	_, result = answer(open(), tea.KeyMsg{Type: tea.KeyEsc})
+	if result.Confirmed {
+		t.Fatal("expected esc to decline")
+	}
+	_, result = answer(open(), tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if !result.Confirmed {
+		t.Fatal("expected y to confirm")
+	}
+}
+
+func TestModalErrorShowsDetailsOnRequest(t *testing.T) {
+	d := ui.NewModal()
+	d.SetWidth(50)
+	d.Error("Export failed", "permission denied", "path: /root/out.png")
+
+	if view := ansi.Strip(d.View()); !strings.Contains(view, "permission denied") || strings.Contains(view, "/root/out.png") {
+		t.Fatalf("expected the message without details, got %q", view)
+	}
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyTab})
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if view := ansi.Strip(d.View()); !strings.Contains(view, "/root/out.png") || !d.Visible {
+		t.Fatalf("expected the details, got %q", view)
+	}
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	d, _ = d.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if d.Visible {
+		t.Fatal("expected OK to close the error")
+	}
+}
+
+func TestModalFollowsItsKeyMap(t *testing.T) {
+	d := ui.NewModal()
+	d.KeyMap.Cancel = key.NewBinding(key.WithKeys("q"), key.WithHelp("q", "cancel"))
+	d.KeyMap.Yes = key.NewBinding(key.WithKeys("o"))
+	d.SetWidth(40)
+	d.Confirm("quit", "Quit?", "Leave Mezzotone", "Quit")
+
+	if view := ansi.Strip(d.View()); !strings.Contains(view, "q cancel") {
+		t.Fatalf("expected the hint to show the remapped key, got %q", view)
+	}
+	d, cmd := d.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd != nil || !d.Visible {
+		t.Fatal("expected esc to do nothing once cancel is remapped")
+	}
+	d, cmd = d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if cmd != nil || !d.Visible {
+		t.Fatal("expected y to do nothing once yes is remapped")
+	}
+	_, cmd = d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	if cmd == nil || !cmd().(ui.ModalResultMsg).Confirmed {
+		t.Fatal("expected the remapped yes key to confirm")
+	}
+}
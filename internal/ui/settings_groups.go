@@ -0,0 +1,167 @@
+package ui
+
+import "strings"
+
+// Condition is met while the item with Key holds Value, ignoring case.
+type Condition struct {
+	Key   string
+	Value string
+}
+
+// panelRow is one selectable line of the settings list: a group header, an item or the
+// confirm button, which has item == len(Items).
+type panelRow struct {
+	item  int
+	group string
+}
+
+func (r panelRow) isHeader() bool {
+	return r.group != ""
+}
+
+// rows lists the visible rows. Groups appear where their first item is, with all of their
+// items below the header unless the group is collapsed.
+func (m *SettingsPanel) rows() []panelRow {
+	var rows []panelRow
+	seen := make(map[string]bool)
+	for i, it := range m.Items {
+		if it.Group == "" {
+			if m.Visible(i) {
+				rows = append(rows, panelRow{item: i})
+			}
+			continue
+		}
+		if seen[it.Group] {
+			continue
+		}
+		seen[it.Group] = true
+		rows = append(rows, panelRow{item: -1, group: it.Group})
+		if m.collapsed[it.Group] {
+			continue
+		}
+		for j := i; j < len(m.Items); j++ {
+			if m.Items[j].Group == it.Group && m.Visible(j) {
+				rows = append(rows, panelRow{item: j})
+			}
+		}
+	}
+	return append(rows, panelRow{item: len(m.Items)})
+}
+
+// ContentRows is how many lines the list needs with every group expanded and every item
+// shown, not counting the title and the confirm button.
+func (m *SettingsPanel) ContentRows() int {
+	groups := make(map[string]bool)
+	for _, it := range m.Items {
+		if it.Group != "" {
+			groups[it.Group] = true
+		}
+	}
+	return len(m.Items) + len(groups)
+}
+
+func (m *SettingsPanel) conditionMet(c *Condition) bool {
+	if c == nil {
+		return true
+	}
+	for _, it := range m.Items {
+		if it.Key == c.Key {
+			return strings.EqualFold(strings.TrimSpace(it.Value), c.Value)
+		}
+	}
+	return true
+}
+
+// Visible reports whether item i is shown, that is its ShowWhen condition is met. Items with
+// a validation error stay visible so the error can be fixed.
+func (m *SettingsPanel) Visible(i int) bool {
+	if i < 0 || i >= len(m.Items) {
+		return false
+	}
+	if _, invalid := m.fieldErrors[m.Items[i].Key]; invalid {
+		return true
+	}
+	return m.conditionMet(m.Items[i].ShowWhen)
+}
+
+// Enabled reports whether item i can be changed, that is its EnableWhen condition is met.
+func (m *SettingsPanel) Enabled(i int) bool {
+	return i >= 0 && i < len(m.Items) && m.conditionMet(m.Items[i].EnableWhen)
+}
+
+// cursorRow is the position of the cursor in rows, or -1 when it is on no visible row.
+func (m *SettingsPanel) cursorRow(rows []panelRow) int {
+	for i, row := range rows {
+		if m.header != "" && row.group == m.header {
+			return i
+		}
+		if m.header == "" && !row.isHeader() && row.item == m.cursor {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *SettingsPanel) moveCursor(dir int) {
+	rows := m.rows()
+	pos := m.cursorRow(rows)
+	if pos < 0 && dir < 0 {
+		return
+	}
+	pos = max(0, min(len(rows)-1, pos+dir))
+	m.setCursorRow(rows[pos])
+}
+
+func (m *SettingsPanel) setCursorRow(row panelRow) {
+	m.header = row.group
+	if !row.isHeader() {
+		m.cursor = row.item
+	}
+	m.Confirm = !row.isHeader() && row.item == len(m.Items)
+}
+
+// currentItem is the item under the cursor, nil on a header or the confirm button.
+func (m *SettingsPanel) currentItem() *SettingItem {
+	if m.header != "" || m.cursor < 0 || m.cursor >= len(m.Items) {
+		return nil
+	}
+	return &m.Items[m.cursor]
+}
+
+// SetCollapsed folds or unfolds group.
+func (m *SettingsPanel) SetCollapsed(group string, collapsed bool) {
+	if m.collapsed == nil {
+		m.collapsed = make(map[string]bool)
+	}
+	m.collapsed[group] = collapsed
+}
+
+func (m *SettingsPanel) Collapsed(group string) bool {
+	return m.collapsed[group]
+}
+
+// groupSize counts the visible items of group for the collapsed header.
+func (m *SettingsPanel) groupSize(group string) int {
+	n := 0
+	for i, it := range m.Items {
+		if it.Group == group && m.Visible(i) {
+			n++
+		}
+	}
+	return n
+}
+
+// scrollTo keeps pos inside the window of height rows starting at m.offset.
+func (m *SettingsPanel) scrollTo(pos, total int) {
+	if m.height <= 0 || total <= m.height {
+		m.offset = 0
+		return
+	}
+	if pos >= 0 && pos < m.offset {
+		m.offset = pos
+	}
+	if pos >= m.offset+m.height {
+		m.offset = pos - m.height + 1
+	}
+	m.offset = max(0, min(m.offset, total-m.height))
+}
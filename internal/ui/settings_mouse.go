@@ -0,0 +1,96 @@
+package ui
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// SettingsConfirmMsg is emitted when the CONFIRM button of a SettingsPanel is clicked.
+type SettingsConfirmMsg struct{}
+
+// panelListTop is the line of the first list row: below the border, the padding, the title and
+// the blank line under it.
+const panelListTop = 4
+
+// updateMouse handles clicks and the wheel. Coordinates are relative to the top left corner of
+// the panel's border. Clicking a bool or an enum changes it right away, clicking the item under
+// the cursor edits it.
+func (m *SettingsPanel) updateMouse(msg tea.MouseMsg) (SettingsPanel, tea.Cmd) {
+	if msg.Action != tea.MouseActionPress {
+		return *m, nil
+	}
+	if m.Editing {
+		if m.editMode == editMultiSelect {
+			m.multiSelectMouse(msg)
+		}
+		return *m, nil
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.errMsg = ""
+		m.moveCursor(-1)
+		return *m, nil
+	case tea.MouseButtonWheelDown:
+		m.errMsg = ""
+		m.moveCursor(+1)
+		return *m, nil
+	case tea.MouseButtonLeft:
+	default:
+		return *m, nil
+	}
+
+	visible, _, _ := m.visibleRows()
+	shown := len(visible)
+	if m.height > 0 {
+		shown = max(shown, m.height)
+	}
+	row := msg.Y - panelListTop
+	if row == shown+1 {
+		m.errMsg = ""
+		m.setCursorRow(panelRow{item: len(m.Items)})
+		return *m, func() tea.Msg { return SettingsConfirmMsg{} }
+	}
+	if row < 0 || row >= len(visible) {
+		return *m, nil
+	}
+
+	r := visible[row]
+	wasCurrent := m.header == "" && !r.isHeader() && m.cursor == r.item
+	m.errMsg = ""
+	m.setCursorRow(r)
+	if r.isHeader() {
+		m.SetCollapsed(r.group, !m.Collapsed(r.group))
+		return *m, nil
+	}
+	if !m.Enabled(r.item) {
+		m.errMsg = m.disabledReason(m.Items[r.item])
+		return *m, nil
+	}
+	switch m.Items[r.item].Type {
+	case TypeBool:
+		m.toggleBool()
+	case TypeEnum:
+		m.stepEnum(+1)
+	default:
+		if wasCurrent {
+			return *m, m.edit(&m.Items[r.item])
+		}
+	}
+	return *m, nil
+}
+
+// multiSelectMouse toggles the clicked option of the checklist shown while editing.
+func (m *SettingsPanel) multiSelectMouse(msg tea.MouseMsg) {
+	options := m.Items[m.cursor].Enum
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.multiCursor = max(0, m.multiCursor-1)
+	case tea.MouseButtonWheelDown:
+		m.multiCursor = max(0, min(len(options)-1, m.multiCursor+1))
+	case tea.MouseButtonLeft:
+		// The first line of the checklist is its title.
+		i := msg.Y - panelListTop - 1
+		if i >= 0 && i < len(options) {
+			m.multiCursor = i
+			m.multiSelected[options[i]] = !m.multiSelected[options[i]]
+		}
+	}
+}
@@ -2,10 +2,13 @@ package ui
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 
 	"codeberg.org/JoaoGarcia/Mezzotone/internal/termtext"
+	"github.com/charmbracelet/bubbles/filepicker"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -18,6 +21,14 @@ const (
 	TypeFloat
 	TypeBool
 	TypeEnum
+	// TypeString is free text on a single line.
+	TypeString
+	// TypeColor is an RGB color, stored as #rrggbb and shown with a swatch.
+	TypeColor
+	// TypePath is a file chosen in a nested picker.
+	TypePath
+	// TypeMultiSelect is any number of Enum options, stored comma separated in Enum order.
+	TypeMultiSelect
 )
 
 type SettingItem struct {
@@ -26,20 +37,84 @@ type SettingItem struct {
 	Label string
 	Value string
 	Enum  []string
+	// Extensions limits TypePath to files with one of these extensions, e.g. ".ttf".
+	Extensions []string
+
+	// Min and Max bound TypeInt and TypeFloat values when Max > Min.
+	// Step is how far left/right nudge them, 1 or 0.1 when unset.
+	Min, Max, Step float64
+
+	// Group puts the item under a collapsible header; items without one are listed on their own.
+	Group string
+	// ShowWhen hides the item while its condition is not met.
+	ShowWhen *Condition
+	// EnableWhen shows the item faint and read-only while its condition is not met.
+	EnableWhen *Condition
+}
+
+// HasRange reports whether the item is a number with Min/Max bounds.
+func (it SettingItem) HasRange() bool {
+	return (it.Type == TypeInt || it.Type == TypeFloat) && it.Max > it.Min
+}
+
+func (it SettingItem) step() float64 {
+	switch {
+	case it.Step > 0:
+		return it.Step
+	case it.Type == TypeInt:
+		return 1
+	default:
+		return 0.1
+	}
+}
+
+// SettingsKeyMap is the keys of a SettingsPanel. Edit also toggles bools, steps enums, folds
+// groups and confirms; Cancel ends an edit without keeping it.
+type SettingsKeyMap struct {
+	Up, Down, Decrease, Increase, Toggle, Edit, Cancel key.Binding
+}
+
+func DefaultSettingsKeyMap() SettingsKeyMap {
+	return SettingsKeyMap{
+		Up:       key.NewBinding(key.WithKeys("k", "up"), key.WithHelp("k/up", "previous")),
+		Down:     key.NewBinding(key.WithKeys("j", "down"), key.WithHelp("j/down", "next")),
+		Decrease: key.NewBinding(key.WithKeys("left"), key.WithHelp("left", "step down")),
+		Increase: key.NewBinding(key.WithKeys("right"), key.WithHelp("right", "step up")),
+		Toggle:   key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "toggle")),
+		Edit:     key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "edit")),
+		Cancel:   key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
+	}
 }
 
 type SettingsPanel struct {
-	Title string
-	Items []SettingItem
+	Title  string
+	Items  []SettingItem
+	KeyMap SettingsKeyMap
+	Theme  Theme
 
+	// cursor is the item index, len(Items) for the confirm button. header is set instead while
+	// the cursor is on a group header.
 	cursor     int
+	header     string
 	Editing    bool
 	beforeEdit string
 	errMsg     string
 	Confirm    bool
 
 	input         textinput.Model
+	editMode      editMode
+	picker        filepicker.Model
+	multiCursor   int
+	multiSelected map[string]bool
 	width, height int
+	// offset is the first list row shown when the list is taller than height.
+	offset    int
+	collapsed map[string]bool
+
+	// compareValues are the values of a pinned render; items that differ are highlighted.
+	compareValues map[string]string
+	// fieldErrors are validation messages by item key, shown until the item is changed.
+	fieldErrors map[string]string
 }
 
 func NewSettingsPanel(title string, items []SettingItem) SettingsPanel {
@@ -48,9 +123,11 @@ func NewSettingsPanel(title string, items []SettingItem) SettingsPanel {
 	ti.CharLimit = 64
 
 	return SettingsPanel{
-		Title: title,
-		Items: items,
-		input: ti,
+		Title:  title,
+		Items:  items,
+		KeyMap: DefaultSettingsKeyMap(),
+		Theme:  DefaultTheme(),
+		input:  ti,
 	}
 }
 
@@ -59,22 +136,48 @@ func (m *SettingsPanel) Init() tea.Cmd {
 }
 
 func (m *SettingsPanel) Update(msg tea.Msg) (SettingsPanel, tea.Cmd) {
+	before := make([]string, len(m.Items))
+	for i, it := range m.Items {
+		before[i] = it.Value
+	}
+	panel, cmd := m.update(msg)
+	for i, it := range m.Items {
+		if it.Value != before[i] {
+			delete(m.fieldErrors, it.Key)
+		}
+	}
+	return panel, cmd
+}
+
+func (m *SettingsPanel) update(msg tea.Msg) (SettingsPanel, tea.Cmd) {
+	if mouse, ok := msg.(tea.MouseMsg); ok {
+		return m.updateMouse(mouse)
+	}
+	if m.Editing && m.editMode == editPath {
+		return m.updatePicker(msg)
+	}
+
 	switch msg := msg.(type) {
 
 	case tea.KeyMsg:
+		if m.Editing && m.editMode == editMultiSelect {
+			return m.updateMultiSelect(msg)
+		}
 		if m.Editing {
-			switch msg.String() {
-			case "esc":
+			switch {
+			case key.Matches(msg, m.KeyMap.Cancel):
 				m.errMsg = ""
-				m.Editing = false
-				m.input.Blur()
-				m.input.SetValue("")
+				m.stopEditing()
 				m.Items[m.cursor].Value = m.beforeEdit
 				return *m, nil
 
-			case "enter":
-				raw := strings.TrimSpace(m.input.Value())
+			case key.Matches(msg, m.KeyMap.Edit):
 				it := &m.Items[m.cursor]
+				raw := m.input.Value()
+				if it.Type != TypeString {
+					// Spaces can be part of free text, like the blank end of a ramp.
+					raw = strings.TrimSpace(raw)
+				}
 
 				if err := validateAndSet(it, raw); err != nil {
 					m.errMsg = err.Error()
@@ -82,9 +185,7 @@ func (m *SettingsPanel) Update(msg tea.Msg) (SettingsPanel, tea.Cmd) {
 				}
 
 				m.errMsg = ""
-				m.Editing = false
-				m.input.Blur()
-				m.input.SetValue("")
+				m.stopEditing()
 				return *m, nil
 
 			default:
@@ -94,50 +195,58 @@ func (m *SettingsPanel) Update(msg tea.Msg) (SettingsPanel, tea.Cmd) {
 			}
 		}
 
-		switch msg.String() {
-		case "up", "k":
-			if m.cursor > 0 {
-				m.Confirm = false
-				m.cursor--
-			}
-			if m.cursor == len(m.Items) {
-				m.Confirm = true
-			}
+		switch {
+		case key.Matches(msg, m.KeyMap.Up):
 			m.errMsg = ""
+			m.moveCursor(-1)
 			return *m, nil
 
-		case "down", "j":
-			if m.cursor < len(m.Items) {
-				m.Confirm = false
-				m.cursor++
-			}
-			if m.cursor == len(m.Items) {
-				m.Confirm = true
-			}
+		case key.Matches(msg, m.KeyMap.Down):
 			m.errMsg = ""
+			m.moveCursor(+1)
+			return *m, nil
+		}
+
+		if m.header != "" {
+			switch {
+			case key.Matches(msg, m.KeyMap.Decrease):
+				m.SetCollapsed(m.header, true)
+			case key.Matches(msg, m.KeyMap.Increase):
+				m.SetCollapsed(m.header, false)
+			case key.Matches(msg, m.KeyMap.Toggle, m.KeyMap.Edit):
+				m.SetCollapsed(m.header, !m.Collapsed(m.header))
+			}
 			return *m, nil
+		}
 
-		case "left", "h":
+		it := m.currentItem()
+		if it == nil {
+			return *m, nil
+		}
+		if key.Matches(msg, m.KeyMap.Decrease, m.KeyMap.Increase, m.KeyMap.Toggle, m.KeyMap.Edit) {
 			m.errMsg = ""
+			if !m.Enabled(m.cursor) {
+				m.errMsg = m.disabledReason(*it)
+				return *m, nil
+			}
+		}
+
+		switch {
+		case key.Matches(msg, m.KeyMap.Decrease):
 			m.stepEnum(-1)
+			m.nudge(-1)
 			return *m, nil
 
-		case "right", "l":
-			m.errMsg = ""
+		case key.Matches(msg, m.KeyMap.Increase):
 			m.stepEnum(+1)
+			m.nudge(+1)
 			return *m, nil
 
-		case " ", "space":
-			m.errMsg = ""
+		case key.Matches(msg, m.KeyMap.Toggle):
 			m.toggleBool()
 			return *m, nil
 
-		case "enter":
-			m.errMsg = ""
-			if m.cursor == len(m.Items) {
-				return *m, nil
-			}
-			it := &m.Items[m.cursor]
+		case key.Matches(msg, m.KeyMap.Edit):
 
 			if it.Type == TypeBool {
 				m.toggleBool()
@@ -147,22 +256,32 @@ func (m *SettingsPanel) Update(msg tea.Msg) (SettingsPanel, tea.Cmd) {
 				m.stepEnum(+1)
 				return *m, nil
 			}
-
-			m.Editing = true
-			m.beforeEdit = it.Value
-			m.input.SetValue(it.Value)
-			m.input.CursorEnd()
-			m.input.Focus()
-			return *m, nil
+			return *m, m.edit(it)
 		}
 	}
 
 	return *m, nil
 }
 
+// edit opens the editor of it: the path picker, the checklist or the text input.
+func (m *SettingsPanel) edit(it *SettingItem) tea.Cmd {
+	switch it.Type {
+	case TypePath:
+		return m.openPicker(it)
+	case TypeMultiSelect:
+		m.openMultiSelect(it)
+		return nil
+	}
+	m.startEditing(it, editText)
+	m.input.SetValue(it.Value)
+	m.input.CursorEnd()
+	m.input.Focus()
+	return nil
+}
+
 func (m *SettingsPanel) View() string {
-	box := lipgloss.NewStyle().
-		Border(lipgloss.NormalBorder()).
+	t := m.Theme
+	box := t.BoxStyle().
 		Padding(1, 2).
 		Width(m.width)
 
@@ -173,7 +292,9 @@ func (m *SettingsPanel) View() string {
 	labelStyle := lipgloss.NewStyle()
 	valueStyle := lipgloss.NewStyle()
 
-	selected := lipgloss.NewStyle().Reverse(true)
+	selected := t.SelectedStyle()
+	changedStyle := t.AccentStyle().Bold(true)
+	invalidStyle := t.ErrorStyle().Bold(true)
 
 	innerW := max(1, m.width-2-4 /*border + padding left+right*/)
 	gapW := 2
@@ -182,36 +303,168 @@ func (m *SettingsPanel) View() string {
 	valueW := min(10, max(1, innerW/3))
 	labelW := max(1, innerW-gapW-valueW)
 
+	// Ranged numbers get a slider at the end of the label column when there is room for one.
+	sliderW := 0
+	if labelW >= 12 {
+		sliderW = min(10, labelW/3)
+	}
+
+	faint := t.FaintStyle()
+	headerStyle := lipgloss.NewStyle().Bold(true)
+
+	visibleRows, above, below := m.visibleRows()
+	if above {
+		title += faint.Render(" ↑")
+	}
+	if below {
+		title += faint.Render(" ↓")
+	}
+
 	lines := []string{title, ""}
+	if m.Editing && m.editMode != editText {
+		editor := m.editorLines(labelW + gapW + valueW)
+		if m.height > 0 {
+			editor = editor[:min(len(editor), m.height)]
+			for len(editor) < m.height {
+				editor = append(editor, "")
+			}
+		}
+		lines = append(lines, editor...)
+		visibleRows = nil
+	}
 
-	for i, it := range m.Items {
-		val := it.Value
+	for _, r := range visibleRows {
+		if r.isHeader() {
+			marker, suffix := "▾ ", ""
+			if m.Collapsed(r.group) {
+				marker, suffix = "▸ ", faint.Render(" ("+strconv.Itoa(m.groupSize(r.group))+")")
+			}
+			row := headerStyle.Render(marker+r.group) + suffix
+			row = lipgloss.NewStyle().Width(labelW + gapW + valueW).Render(termtext.TruncateLinesANSI(row, labelW+gapW+valueW))
+			if m.header == r.group {
+				row = selected.Render(row)
+			}
+			lines = append(lines, row)
+			continue
+		}
+
+		i, it := r.item, m.Items[r.item]
+		val := m.displayValue(it, valueW)
 		if m.Editing && i == m.cursor {
 			m.input.Width = valueW
 			val = m.input.View()
 		}
 
-		left := labelStyle.MaxWidth(labelW).Width(labelW).Render(termtext.TruncateLinesANSI(it.Label, labelW))
-		right := valueStyle.Width(valueW).Render(val)
+		label := it.Label
+		style := valueStyle
+		if pinned, ok := m.compareValues[it.Key]; ok && !sameSettingValue(it, pinned) {
+			label += " (A: " + pinned + ")"
+			style = changedStyle
+		}
+		if _, invalid := m.fieldErrors[it.Key]; invalid {
+			label = "⚠ " + label
+			style = invalidStyle
+		}
+		if it.Group != "" {
+			label = "  " + label
+		}
+		if !m.Enabled(i) {
+			style = faint
+			label = faint.Render(label)
+		}
+
+		var left string
+		if it.HasRange() && sliderW > 0 {
+			textW := labelW - sliderW - 1
+			left = labelStyle.Width(textW).Render(termtext.TruncateLinesANSI(label, textW)) + " " + m.slider(it, sliderW)
+		} else {
+			left = labelStyle.MaxWidth(labelW).Width(labelW).Render(termtext.TruncateLinesANSI(label, labelW))
+		}
+		right := style.Width(valueW).Render(val)
 
 		row := left + strings.Repeat(" ", gapW) + right
-		if i == m.cursor {
+		if m.header == "" && i == m.cursor {
 			row = selected.Render(row)
 		}
 		lines = append(lines, row)
 	}
+	if m.height > 0 && !(m.Editing && m.editMode != editText) {
+		for range m.height - len(visibleRows) {
+			lines = append(lines, "")
+		}
+	}
 
 	confirmButton := labelStyle.Width(labelW + valueW).Render("CONFIRM")
-	if m.cursor == len(m.Items) {
+	if m.header == "" && m.cursor == len(m.Items) {
 		confirmButton = selected.Render(confirmButton)
 	}
 	lines = append(lines, "\n"+confirmButton)
 	return box.Render(strings.Join(lines, "\n"))
 }
 
+// visibleRows is the window of list rows shown below the title, scrolled to the cursor, and
+// whether rows are hidden above or below it.
+func (m *SettingsPanel) visibleRows() (visible []panelRow, above, below bool) {
+	rows := m.rows()
+	listRows := rows[:len(rows)-1]
+	m.scrollTo(m.cursorRow(listRows), len(listRows))
+	if m.height <= 0 || len(listRows) <= m.height {
+		return listRows, false, false
+	}
+	return listRows[m.offset : m.offset+m.height], m.offset > 0, m.offset+m.height < len(listRows)
+}
+
+// SetCompareValues highlights every item whose value differs from values; nil clears it.
+func (m *SettingsPanel) SetCompareValues(values map[string]string) {
+	m.compareValues = values
+}
+
+// sameSettingValue compares numbers numerically and everything else case-insensitively,
+// so "0.6" matches "0.60" and "TRUE" matches "true".
+func sameSettingValue(it SettingItem, other string) bool {
+	if it.Type == TypeInt || it.Type == TypeFloat {
+		a, errA := strconv.ParseFloat(strings.TrimSpace(it.Value), 64)
+		b, errB := strconv.ParseFloat(strings.TrimSpace(other), 64)
+		if errA == nil && errB == nil {
+			return a == b
+		}
+	}
+	if it.Type == TypeColor {
+		a, errA := ParseColor(it.Value)
+		b, errB := ParseColor(other)
+		if errA == nil && errB == nil {
+			return a == b
+		}
+	}
+	return strings.EqualFold(strings.TrimSpace(it.Value), strings.TrimSpace(other))
+}
+
+// slider draws where the item's value sits between Min and Max, e.g. ━━━●──────.
+func (m *SettingsPanel) slider(it SettingItem, width int) string {
+	v, err := strconv.ParseFloat(strings.TrimSpace(it.Value), 64)
+	if err != nil {
+		v = it.Min
+	}
+	frac := (math.Max(it.Min, math.Min(it.Max, v)) - it.Min) / (it.Max - it.Min)
+	knob := int(math.Round(frac * float64(width-1)))
+
+	return strings.Repeat("━", knob) + "●" +
+		m.Theme.FaintStyle().Render(strings.Repeat("─", width-1-knob))
+}
+
+// disabledReason explains which setting has to change before it can be edited.
+func (m *SettingsPanel) disabledReason(it SettingItem) string {
+	for _, other := range m.Items {
+		if other.Key == it.EnableWhen.Key {
+			return fmt.Sprintf("%s needs %s set to %s", it.Label, other.Label, it.EnableWhen.Value)
+		}
+	}
+	return it.Label + " is disabled"
+}
+
 func (m *SettingsPanel) toggleBool() {
-	it := &m.Items[m.cursor]
-	if it.Type != TypeBool {
+	it := m.currentItem()
+	if it == nil || it.Type != TypeBool {
 		return
 	}
 	switch strings.ToLower(strings.TrimSpace(it.Value)) {
@@ -223,8 +476,8 @@ func (m *SettingsPanel) toggleBool() {
 }
 
 func (m *SettingsPanel) stepEnum(dir int) {
-	it := &m.Items[m.cursor]
-	if it.Type != TypeEnum || len(it.Enum) == 0 {
+	it := m.currentItem()
+	if it == nil || it.Type != TypeEnum || len(it.Enum) == 0 {
 		return
 	}
 	cur := indexOf(it.Enum, it.Value)
@@ -238,19 +491,76 @@ func (m *SettingsPanel) stepEnum(dir int) {
 	it.Value = it.Enum[next]
 }
 
+// nudge moves a numeric value by dir steps, staying within its range.
+func (m *SettingsPanel) nudge(dir int) {
+	it := m.currentItem()
+	if it == nil || (it.Type != TypeInt && it.Type != TypeFloat) {
+		return
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(it.Value), 64)
+	if err != nil {
+		v = it.Min
+	}
+	v += float64(dir) * it.step()
+	if it.HasRange() {
+		v = math.Max(it.Min, math.Min(it.Max, v))
+	}
+	it.Value = formatNumber(*it, v)
+}
+
+// formatNumber prints v with no more decimals than the item's step and range bounds, so
+// repeated nudges do not show floating point noise like 0.30000000000000004 and a clamped
+// value such as a minimum of 0.25 is not rounded.
+func formatNumber(it SettingItem, v float64) string {
+	if it.Type == TypeInt {
+		return strconv.Itoa(int(math.Round(v)))
+	}
+	decimals := 0
+	precise := []string{strconv.FormatFloat(it.step(), 'f', -1, 64), strings.TrimSpace(it.Value)}
+	if it.HasRange() {
+		precise = append(precise, strconv.FormatFloat(it.Min, 'f', -1, 64), strconv.FormatFloat(it.Max, 'f', -1, 64))
+	}
+	for _, s := range precise {
+		if _, frac, ok := strings.Cut(s, "."); ok {
+			decimals = max(decimals, len(frac))
+		}
+	}
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	if strings.Contains(s, ".") {
+		s = strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// checkRange names the allowed range when v is outside of it.
+func checkRange(it SettingItem, v float64) error {
+	if it.HasRange() && (v < it.Min || v > it.Max) {
+		return fmt.Errorf("must be between %s and %s", formatNumber(it, it.Min), formatNumber(it, it.Max))
+	}
+	return nil
+}
+
 func validateAndSet(it *SettingItem, raw string) error {
 	switch it.Type {
 	case TypeInt:
-		if _, err := strconv.Atoi(raw); err != nil {
+		v, err := strconv.Atoi(raw)
+		if err != nil {
 			return fmt.Errorf("must be an integer")
 		}
+		if err := checkRange(*it, float64(v)); err != nil {
+			return err
+		}
 		it.Value = raw
 		return nil
 
 	case TypeFloat:
-		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
 			return fmt.Errorf("must be a number")
 		}
+		if err := checkRange(*it, v); err != nil {
+			return err
+		}
 		it.Value = raw
 		return nil
 
@@ -272,6 +582,37 @@ func validateAndSet(it *SettingItem, raw string) error {
 		}
 		return fmt.Errorf("must be one of: %s", strings.Join(it.Enum, ", "))
 
+	case TypeString:
+		if err := validateString(raw); err != nil {
+			return err
+		}
+		it.Value = raw
+		return nil
+
+	case TypeColor:
+		c, err := ParseColor(raw)
+		if err != nil {
+			return fmt.Errorf("must be a color like #ff8800 or 255,136,0")
+		}
+		it.Value = FormatColor(c)
+		return nil
+
+	case TypePath:
+		path, err := validatePath(*it, raw)
+		if err != nil {
+			return err
+		}
+		it.Value = path
+		return nil
+
+	case TypeMultiSelect:
+		value, err := validateMultiSelect(*it, raw)
+		if err != nil {
+			return err
+		}
+		it.Value = value
+		return nil
+
 	default:
 		it.Value = raw
 		return nil
@@ -306,12 +647,34 @@ func (m *SettingsPanel) SetHeight(h int) {
 
 func (m *SettingsPanel) ClearActive() {
 	m.cursor = -1
+	m.header = ""
 }
 
+// SetActive puts the cursor on item i, or on the confirm button for len(Items), unfolding the
+// item's group.
 func (m *SettingsPanel) SetActive(i int) {
 	m.cursor = i
+	m.header = ""
+	if i >= 0 && i < len(m.Items) && m.Items[i].Group != "" {
+		m.SetCollapsed(m.Items[i].Group, false)
+	}
 }
 
+// ErrorMessage is the error of the current edit or, failing that, the validation error of the
+// item under the cursor.
 func (m *SettingsPanel) ErrorMessage() string {
-	return m.errMsg
+	if m.errMsg != "" {
+		return m.errMsg
+	}
+	if it := m.currentItem(); it != nil {
+		if msg, ok := m.fieldErrors[it.Key]; ok {
+			return it.Label + " " + msg
+		}
+	}
+	return ""
+}
+
+// SetFieldErrors marks items by key as invalid with a message each; nil clears them.
+func (m *SettingsPanel) SetFieldErrors(errors map[string]string) {
+	m.fieldErrors = errors
 }
@@ -1,6 +1,7 @@
 package ui_test
 
 import (
+	"strings"
 	"testing"
 
 	"codeberg.org/JoaoGarcia/Mezzotone/internal/ui"
@@ -128,3 +129,231 @@ func TestSettingsPanelInvalidFloatKeepsEditingAndValue(t *testing.T) {
 		t.Fatalf("invalid input should not change stored value, got %q", m.Items[1].Value)
 	}
 }
+
+func TestSettingsPanelHighlightsValuesThatDifferFromPinned(t *testing.T) {
+	m := newRenderSettingsPanelForTests()
+	m.SetWidth(60)
+	m.SetCompareValues(map[string]string{
+		"textSize":          "10",
+		"fontAspect":        "2.30",
+		"directionalRender": "true",
+		"runeMode":          "DOTS",
+	})
+
+	view := m.View()
+	if !strings.Contains(view, "Rune Mode (A: DOTS)") || !strings.Contains(view, "Directional Render (A: true)") {
+		t.Fatalf("expected differing items to show the pinned value, got %q", view)
+	}
+	if strings.Contains(view, "(A: 2.30)") || strings.Contains(view, "(A: 10)") {
+		t.Fatalf("equal values must not be marked, got %q", view)
+	}
+
+	m.SetCompareValues(nil)
+	if strings.Contains(m.View(), "(A:") {
+		t.Fatalf("expected no markers after clearing")
+	}
+}
+
+func newRangedSettingsPanelForTests() ui.SettingsPanel {
+	m := ui.NewSettingsPanel("Render Options", []ui.SettingItem{
+		{Label: "Text Size", Key: "textSize", Type: ui.TypeInt, Value: "2", Min: 1, Max: 100, Step: 1},
+		{Label: "Edge Threshold", Key: "edgeThreshold", Type: ui.TypeFloat, Value: "0.9", Min: 0, Max: 1, Step: 0.05},
+	})
+	m.SetWidth(60)
+	return m
+}
+
+func TestSettingsPanelLeftRightNudgeWithinRange(t *testing.T) {
+	m := newRangedSettingsPanelForTests()
+	m.SetActive(0)
+	for range 3 {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	}
+	if m.Items[0].Value != "1" {
+		t.Fatalf("expected text size clamped to 1, got %q", m.Items[0].Value)
+	}
+
+	m.SetActive(1)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if m.Items[1].Value != "0.95" {
+		t.Fatalf("expected 0.95 after one step, got %q", m.Items[1].Value)
+	}
+	for range 5 {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	}
+	if m.Items[1].Value != "1" {
+		t.Fatalf("expected edge threshold clamped to 1, got %q", m.Items[1].Value)
+	}
+}
+
+func TestSettingsPanelNudgeKeepsThePrecisionOfTheBounds(t *testing.T) {
+	m := ui.NewSettingsPanel("Render Options", []ui.SettingItem{
+		{Label: "Font Aspect", Key: "fontAspect", Type: ui.TypeFloat, Value: "0.4", Min: 0.25, Max: 8, Step: 0.1},
+	})
+	m.SetWidth(60)
+	m.SetActive(0)
+	for range 3 {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	}
+	if m.Items[0].Value != "0.25" {
+		t.Fatalf("expected font aspect clamped to 0.25, got %q", m.Items[0].Value)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if m.Items[0].Value != "0.35" {
+		t.Fatalf("expected 0.35 after one step up, got %q", m.Items[0].Value)
+	}
+}
+
+func TestSettingsPanelRejectsValuesOutsideRange(t *testing.T) {
+	m := newRangedSettingsPanelForTests()
+	m.SetActive(1)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	for range 3 {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("40")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.ErrorMessage() != "must be between 0 and 1" || m.Items[1].Value != "0.9" || !m.Editing {
+		t.Fatalf("expected a range error and the old value, got %q %q", m.ErrorMessage(), m.Items[1].Value)
+	}
+}
+
+func TestSettingsPanelViewDrawsSliderForRangedItems(t *testing.T) {
+	m := newRangedSettingsPanelForTests()
+	if got := strings.Count(m.View(), "●"); got != 2 {
+		t.Fatalf("expected a slider knob per ranged item, got %d", got)
+	}
+}
+
+func TestSettingsPanelFieldErrorsShowUntilTheItemChanges(t *testing.T) {
+	m := newRenderSettingsPanelForTests()
+	m.SetFieldErrors(map[string]string{"directionalRender": "is broken"})
+	m.SetActive(2)
+
+	if m.ErrorMessage() != "Directional Render is broken" {
+		t.Fatalf("unexpected error message %q", m.ErrorMessage())
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.ErrorMessage() != "" || strings.Contains(m.View(), "⚠") {
+		t.Fatalf("expected the error to clear after toggling the value")
+	}
+}
+
+func newGroupedSettingsPanelForTests() ui.SettingsPanel {
+	directional := &ui.Condition{Key: "directionalRender", Value: "TRUE"}
+	m := ui.NewSettingsPanel("Render Options", []ui.SettingItem{
+		{Label: "Text Size", Key: "textSize", Type: ui.TypeInt, Value: "10", Group: "Sampling"},
+		{Label: "Directional Render", Key: "directionalRender", Type: ui.TypeBool, Value: "FALSE", Group: "Edges"},
+		{Label: "Edge Threshold", Key: "edgeThreshold", Type: ui.TypeFloat, Value: "0.6", Group: "Edges", ShowWhen: directional},
+		{Label: "Edge Glyphs", Key: "edgeGlyphs", Type: ui.TypeBool, Value: "TRUE", Group: "Edges", EnableWhen: directional},
+	})
+	m.SetWidth(60)
+	return m
+}
+
+func TestSettingsPanelShowWhenHidesItemsUntilConditionIsMet(t *testing.T) {
+	m := newGroupedSettingsPanelForTests()
+	if strings.Contains(m.View(), "Edge Threshold") {
+		t.Fatalf("edge threshold should be hidden while directional render is off")
+	}
+
+	m.SetActive(1)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace})
+	if !strings.Contains(m.View(), "Edge Threshold") {
+		t.Fatalf("edge threshold should show once directional render is on")
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if m.Items[2].Value != "0.7" {
+		t.Fatalf("expected down to reach the revealed item, got %q", m.Items[2].Value)
+	}
+}
+
+func TestSettingsPanelEnableWhenBlocksEdits(t *testing.T) {
+	m := newGroupedSettingsPanelForTests()
+	m.SetActive(3)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace})
+	if m.Items[3].Value != "TRUE" || m.ErrorMessage() != "Edge Glyphs needs Directional Render set to TRUE" {
+		t.Fatalf("expected a disabled item to stay unchanged, got %q %q", m.Items[3].Value, m.ErrorMessage())
+	}
+}
+
+func TestSettingsPanelGroupHeadersCollapse(t *testing.T) {
+	m := newGroupedSettingsPanelForTests()
+	m.SetActive(0)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.Collapsed("Edges") || strings.Contains(m.View(), "Directional Render") {
+		t.Fatalf("expected enter on the Edges header to fold it")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if !m.Confirm {
+		t.Fatalf("expected the folded items to be skipped")
+	}
+
+	m.SetActive(1)
+	if m.Collapsed("Edges") {
+		t.Fatalf("expected SetActive to unfold the item's group")
+	}
+}
+
+func TestSettingsPanelScrollsToKeepCursorVisible(t *testing.T) {
+	m := newGroupedSettingsPanelForTests()
+	m.SetHeight(2)
+	m.SetActive(0)
+	if view := m.View(); !strings.Contains(view, "Text Size") || strings.Contains(view, "Directional Render") || !strings.Contains(view, "↓") {
+		t.Fatalf("expected the first two rows and a scroll marker, got %q", view)
+	}
+
+	m.SetActive(1)
+	if view := m.View(); strings.Contains(view, "Text Size") || !strings.Contains(view, "Directional Render") || !strings.Contains(view, "↑") {
+		t.Fatalf("expected the list to scroll down to the cursor, got %q", view)
+	}
+}
+
+func TestSettingsPanelMouseClicksRowsAndConfirm(t *testing.T) {
+	m := newRenderSettingsPanelForTests()
+	m.SetWidth(40)
+	m.ClearActive()
+	click := func(y int) tea.Cmd {
+		var cmd tea.Cmd
+		m, cmd = m.Update(tea.MouseMsg{X: 3, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+		return cmd
+	}
+	// Border, padding, title and a blank line come before the first row.
+	const firstRow = 4
+
+	click(firstRow + 3)
+	if m.Items[3].Value != "UNICODE" {
+		t.Fatalf("expected clicking an enum to step it, got %s", m.Items[3].Value)
+	}
+	click(firstRow)
+	if m.Editing {
+		t.Fatalf("the first click on a number should only move the cursor")
+	}
+	click(firstRow)
+	if !m.Editing {
+		t.Fatalf("clicking the current number should edit it")
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	m, _ = m.Update(tea.MouseMsg{Y: firstRow, Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown})
+	click(firstRow + 1)
+	if !m.Editing {
+		t.Fatalf("the wheel should move the cursor to the next item")
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	cmd := click(firstRow + len(m.Items) + 1)
+	if !m.Confirm || cmd == nil {
+		t.Fatalf("clicking CONFIRM should select it and emit a message")
+	}
+	if _, ok := cmd().(ui.SettingsConfirmMsg); !ok {
+		t.Fatalf("expected SettingsConfirmMsg")
+	}
+}
@@ -0,0 +1,315 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/filepicker"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// editMode is what the panel shows while Editing.
+type editMode int
+
+const (
+	editText editMode = iota
+	editPath
+	editMultiSelect
+)
+
+// ParseColor reads #rgb, #rrggbb (the # is optional), "r,g,b" and "rgb(r, g, b)".
+func ParseColor(s string) (color.RGBA, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	invalid := fmt.Errorf("invalid color %q", s)
+
+	if inner, ok := strings.CutPrefix(s, "rgb("); ok {
+		s, ok = strings.CutSuffix(inner, ")")
+		if !ok {
+			return color.RGBA{}, invalid
+		}
+	}
+	if strings.Contains(s, ",") {
+		parts := strings.Split(s, ",")
+		if len(parts) != 3 {
+			return color.RGBA{}, invalid
+		}
+		var rgb [3]uint8
+		for i, part := range parts {
+			v, err := strconv.ParseUint(strings.TrimSpace(part), 10, 8)
+			if err != nil {
+				return color.RGBA{}, invalid
+			}
+			rgb[i] = uint8(v)
+		}
+		return color.RGBA{R: rgb[0], G: rgb[1], B: rgb[2], A: 0xff}, nil
+	}
+
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return color.RGBA{}, invalid
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, invalid
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, nil
+}
+
+// FormatColor is how TypeColor values are stored, e.g. #ff8800.
+func FormatColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// SelectedOptions splits a TypeMultiSelect value into its options.
+func SelectedOptions(value string) []string {
+	var selected []string
+	for _, option := range strings.Split(value, ",") {
+		if option = strings.TrimSpace(option); option != "" {
+			selected = append(selected, option)
+		}
+	}
+	return selected
+}
+
+func validateString(raw string) error {
+	if strings.ContainsFunc(raw, unicode.IsControl) {
+		return fmt.Errorf("must be a single line of text")
+	}
+	return nil
+}
+
+func validatePath(it SettingItem, raw string) (string, error) {
+	if home, err := os.UserHomeDir(); err == nil {
+		if rest, ok := strings.CutPrefix(raw, "~/"); ok {
+			raw = filepath.Join(home, rest)
+		}
+	}
+	info, err := os.Stat(raw)
+	if err != nil {
+		return "", fmt.Errorf("file not found: %s", raw)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("must be a file, not a directory")
+	}
+	if len(it.Extensions) > 0 && !hasExtension(raw, it.Extensions) {
+		return "", fmt.Errorf("must be a %s file", strings.Join(it.Extensions, ", "))
+	}
+	return raw, nil
+}
+
+func hasExtension(path string, extensions []string) bool {
+	ext := filepath.Ext(path)
+	for _, allowed := range extensions {
+		if strings.EqualFold(ext, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateMultiSelect normalizes raw to the matching Enum options in Enum order.
+func validateMultiSelect(it SettingItem, raw string) (string, error) {
+	chosen := make(map[string]bool)
+	for _, option := range SelectedOptions(raw) {
+		i := indexOfFold(it.Enum, option)
+		if i < 0 {
+			return "", fmt.Errorf("unknown option %s, must be any of: %s", option, strings.Join(it.Enum, ", "))
+		}
+		chosen[it.Enum[i]] = true
+	}
+	var selected []string
+	for _, option := range it.Enum {
+		if chosen[option] {
+			selected = append(selected, option)
+		}
+	}
+	return strings.Join(selected, ","), nil
+}
+
+func indexOfFold(xs []string, v string) int {
+	for i := range xs {
+		if strings.EqualFold(xs[i], v) {
+			return i
+		}
+	}
+	return -1
+}
+
+// displayValue is how an item's value is shown in its row, fitted to width.
+func (m *SettingsPanel) displayValue(it SettingItem, width int) string {
+	faint := m.Theme.FaintStyle()
+	switch it.Type {
+	case TypeColor:
+		if c, err := ParseColor(it.Value); err == nil {
+			swatch := lipgloss.NewStyle().Foreground(lipgloss.Color(FormatColor(c))).Render("██")
+			return swatch + " " + ansi.Truncate(FormatColor(c), max(0, width-3), "…")
+		}
+	case TypePath:
+		if it.Value == "" {
+			return faint.Render("none")
+		}
+		return ansi.Truncate(filepath.Base(it.Value), width, "…")
+	case TypeMultiSelect:
+		selected := SelectedOptions(it.Value)
+		switch {
+		case len(selected) == 0:
+			return faint.Render("none")
+		case ansi.StringWidth(strings.Join(selected, ", ")) > width:
+			return fmt.Sprintf("%d selected", len(selected))
+		}
+		return strings.Join(selected, ", ")
+	}
+	return ansi.Truncate(it.Value, width, "…")
+}
+
+// openPicker starts choosing a file for the item under the cursor, in the directory of its
+// current value when there is one.
+func (m *SettingsPanel) openPicker(it *SettingItem) tea.Cmd {
+	fp := filepicker.New()
+	fp.AutoHeight = false
+	fp.AllowedTypes = it.Extensions
+	fp.ShowPermissions = false
+	fp.ShowSize = false
+	// The picker follows the panel's keys: Increase and Edit open directories, Edit selects.
+	fp.KeyMap = filepicker.KeyMap{
+		GoToTop:  key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "first")),
+		GoToLast: key.NewBinding(key.WithKeys("G"), key.WithHelp("G", "last")),
+		Down:     m.KeyMap.Down,
+		Up:       m.KeyMap.Up,
+		PageUp:   key.NewBinding(key.WithKeys("pgup"), key.WithHelp("pgup", "page up")),
+		PageDown: key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("pgdown", "page down")),
+		Back:     key.NewBinding(key.WithKeys(slices.Concat(m.KeyMap.Decrease.Keys(), []string{"backspace"})...)),
+		Open:     key.NewBinding(key.WithKeys(slices.Concat(m.KeyMap.Increase.Keys(), m.KeyMap.Edit.Keys())...)),
+		Select:   m.KeyMap.Edit,
+	}
+	fp.CurrentDirectory, _ = os.UserHomeDir()
+	if dir := filepath.Dir(it.Value); it.Value != "" {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			fp.CurrentDirectory = dir
+		}
+	}
+	fp.SetHeight(max(3, m.height-1))
+
+	m.picker = fp
+	m.startEditing(it, editPath)
+	return fp.Init()
+}
+
+func (m *SettingsPanel) updatePicker(msg tea.Msg) (SettingsPanel, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && key.Matches(keyMsg, m.KeyMap.Cancel) {
+		m.stopEditing()
+		return *m, nil
+	}
+
+	var cmd tea.Cmd
+	m.picker, cmd = m.picker.Update(msg)
+	if didSelect, path := m.picker.DidSelectFile(msg); didSelect {
+		if err := validateAndSet(&m.Items[m.cursor], path); err != nil {
+			m.errMsg = err.Error()
+			return *m, cmd
+		}
+		m.stopEditing()
+		return *m, cmd
+	}
+	if didSelect, _ := m.picker.DidSelectDisabledFile(msg); didSelect {
+		m.errMsg = fmt.Sprintf("must be a %s file", strings.Join(m.Items[m.cursor].Extensions, ", "))
+	}
+	return *m, cmd
+}
+
+func (m *SettingsPanel) openMultiSelect(it *SettingItem) {
+	m.multiSelected = make(map[string]bool)
+	for _, option := range SelectedOptions(it.Value) {
+		m.multiSelected[option] = true
+	}
+	m.multiCursor = 0
+	m.startEditing(it, editMultiSelect)
+}
+
+func (m *SettingsPanel) updateMultiSelect(msg tea.KeyMsg) (SettingsPanel, tea.Cmd) {
+	it := &m.Items[m.cursor]
+	switch {
+	case key.Matches(msg, m.KeyMap.Cancel):
+		m.stopEditing()
+	case key.Matches(msg, m.KeyMap.Up):
+		m.multiCursor = max(0, m.multiCursor-1)
+	case key.Matches(msg, m.KeyMap.Down):
+		m.multiCursor = max(0, min(len(it.Enum)-1, m.multiCursor+1))
+	case key.Matches(msg, m.KeyMap.Toggle):
+		if m.multiCursor >= 0 && m.multiCursor < len(it.Enum) {
+			option := it.Enum[m.multiCursor]
+			m.multiSelected[option] = !m.multiSelected[option]
+		}
+	case key.Matches(msg, m.KeyMap.Edit):
+		var selected []string
+		for _, option := range it.Enum {
+			if m.multiSelected[option] {
+				selected = append(selected, option)
+			}
+		}
+		if err := validateAndSet(it, strings.Join(selected, ",")); err != nil {
+			m.errMsg = err.Error()
+			return *m, nil
+		}
+		m.stopEditing()
+	}
+	return *m, nil
+}
+
+func (m *SettingsPanel) startEditing(it *SettingItem, mode editMode) {
+	m.errMsg = ""
+	m.Editing = true
+	m.editMode = mode
+	m.beforeEdit = it.Value
+}
+
+func (m *SettingsPanel) stopEditing() {
+	m.Editing = false
+	m.editMode = editText
+	m.input.Blur()
+	m.input.SetValue("")
+}
+
+// editorLines is the nested picker or checklist that replaces the list while editing.
+func (m *SettingsPanel) editorLines(width int) []string {
+	it := m.Items[m.cursor]
+	faint := m.Theme.FaintStyle()
+	selected := m.Theme.SelectedStyle()
+
+	switch m.editMode {
+	case editPath:
+		lines := []string{faint.Render(ansi.Truncate("Choose "+it.Label+" · "+m.KeyMap.Cancel.Help().Key+" cancel", width, "…"))}
+		for _, line := range strings.Split(strings.TrimRight(m.picker.View(), "\n"), "\n") {
+			lines = append(lines, ansi.Truncate(line, width, "…"))
+		}
+		return lines
+
+	case editMultiSelect:
+		lines := []string{faint.Render(ansi.Truncate(it.Label+" · "+m.KeyMap.Toggle.Help().Key+" toggle · "+m.KeyMap.Edit.Help().Key+" done", width, "…"))}
+		for i, option := range it.Enum {
+			box := "[ ] "
+			if m.multiSelected[option] {
+				box = "[x] "
+			}
+			line := ansi.Truncate(box+option, width, "…")
+			if i == m.multiCursor {
+				line = selected.Render(line)
+			}
+			lines = append(lines, line)
+		}
+		return lines
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package ui_test
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/ui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestParseColorAcceptsHexAndRGB(t *testing.T) {
+	want := color.RGBA{R: 0xff, G: 0x88, B: 0x00, A: 0xff}
+	for _, in := range []string{"#ff8800", "FF8800", "#f80", "255,136,0", "rgb(255, 136, 0)"} {
+		got, err := ui.ParseColor(in)
+		if err != nil {
+			t.Fatalf("ParseColor(%q): %v", in, err)
+		}
+		if got != want {
+			t.Fatalf("ParseColor(%q) = %v, want %v", in, got, want)
+		}
+	}
+	for _, in := range []string{"", "#ff88", "zzzzzz", "256,0,0", "1,2", "rgb(1,2,3"} {
+		if _, err := ui.ParseColor(in); err == nil {
+			t.Fatalf("ParseColor(%q) should fail", in)
+		}
+	}
+}
+
+func TestSettingsPanelColorEditNormalizesAndShowsSwatch(t *testing.T) {
+	m := ui.NewSettingsPanel("Colors", []ui.SettingItem{
+		{Label: "Background", Key: "background", Type: ui.TypeColor, Value: "#000000"},
+	})
+	m.SetWidth(40)
+	m.SetActive(0)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlU})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("nope")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.Editing || m.ErrorMessage() == "" {
+		t.Fatalf("invalid color should keep editing with an error, got editing=%v err=%q", m.Editing, m.ErrorMessage())
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlU})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("255,136,0")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.Editing {
+		t.Fatalf("valid color should end editing, got error %q", m.ErrorMessage())
+	}
+	if m.Items[0].Value != "#ff8800" {
+		t.Fatalf("color = %q, want #ff8800", m.Items[0].Value)
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "██ #ff8800") {
+		t.Fatalf("view should show a swatch next to the color:\n%s", view)
+	}
+}
+
+func TestSettingsPanelStringEditSaveAndCancel(t *testing.T) {
+	m := ui.NewSettingsPanel("Glyphs", []ui.SettingItem{
+		{Label: "Ramp", Key: "ramp", Type: ui.TypeString, Value: " .:-=+*#%@"},
+	})
+	m.SetActive(0)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlU})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" .oO@")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.Editing || m.Items[0].Value != " .oO@" {
+		t.Fatalf("enter should save the text, got editing=%v value=%q", m.Editing, m.Items[0].Value)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("xyz")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.Editing || m.Items[0].Value != " .oO@" {
+		t.Fatalf("esc should cancel and keep the value, got editing=%v value=%q", m.Editing, m.Items[0].Value)
+	}
+}
+
+func TestSettingsPanelMultiSelectTogglesOptions(t *testing.T) {
+	m := ui.NewSettingsPanel("Filters", []ui.SettingItem{
+		{Label: "Filters", Key: "filters", Type: ui.TypeMultiSelect, Value: "sharpen", Enum: []string{"blur", "sharpen", "invert"}},
+	})
+	m.SetWidth(40)
+	m.SetActive(0)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.Editing {
+		t.Fatalf("enter should open the option list")
+	}
+	if view := m.View(); !strings.Contains(view, "[x] sharpen") || !strings.Contains(view, "[ ] blur") {
+		t.Fatalf("option list should mark the current selection:\n%s", view)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.Editing {
+		t.Fatalf("enter should close the option list")
+	}
+	if m.Items[0].Value != "blur,sharpen,invert" {
+		t.Fatalf("value = %q, want options in list order", m.Items[0].Value)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.Editing || m.Items[0].Value != "blur,sharpen,invert" {
+		t.Fatalf("esc should discard toggles, got editing=%v value=%q", m.Editing, m.Items[0].Value)
+	}
+}
+
+func TestSettingsPanelMultiSelectWithoutOptions(t *testing.T) {
+	m := ui.NewSettingsPanel("Filters", []ui.SettingItem{
+		{Label: "Filters", Key: "filters", Type: ui.TypeMultiSelect},
+	})
+	m.SetWidth(40)
+	m.SetActive(0)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	m, _ = m.Update(tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.Editing || m.Items[0].Value != "" {
+		t.Fatalf("expected an empty list to close with no value, got editing=%v value=%q", m.Editing, m.Items[0].Value)
+	}
+}
+
+func TestSettingsPanelPathOpensPickerAndChecksExtension(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "font.ttf"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := ui.NewSettingsPanel("Export", []ui.SettingItem{
+		{Label: "Font", Key: "font", Type: ui.TypePath, Value: filepath.Join(dir, "missing.ttf"), Extensions: []string{".ttf"}},
+	})
+	m.SetWidth(50)
+	m.SetHeight(8)
+	m.SetActive(0)
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.Editing || cmd == nil {
+		t.Fatalf("enter should open the file picker")
+	}
+	m, _ = m.Update(cmd())
+	if view := m.View(); !strings.Contains(view, "font.ttf") {
+		t.Fatalf("picker should list the directory of the current value:\n%s", view)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.Editing {
+		t.Fatalf("selecting a .ttf file should close the picker, error %q", m.ErrorMessage())
+	}
+	if want := filepath.Join(dir, "font.ttf"); m.Items[0].Value != want {
+		t.Fatalf("value = %q, want %q", m.Items[0].Value, want)
+	}
+	if view := m.View(); !strings.Contains(view, "font.ttf") {
+		t.Fatalf("row should show the file name:\n%s", view)
+	}
+}
@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme names the colors of the TUI by role. An empty color keeps the terminal's own: no
+// Selection reverses the text, no Faint dims it and no Border draws borders in the text color.
+type Theme struct {
+	// Accent marks the spinner and changed values.
+	Accent lipgloss.Color
+	// Error colors error messages and invalid values.
+	Error     lipgloss.Color
+	Border    lipgloss.Color
+	Selection lipgloss.Color
+	// Faint is for hints, captions and inactive entries.
+	Faint lipgloss.Color
+}
+
+// Names of the built-in themes. ThemeAuto picks ThemeDark or ThemeLight for the terminal
+// background.
+const (
+	ThemeAuto         = "auto"
+	ThemeDark         = "dark"
+	ThemeLight        = "light"
+	ThemeHighContrast = "high-contrast"
+)
+
+var builtinThemes = map[string]Theme{
+	ThemeDark: {Accent: "205", Error: "9"},
+	ThemeLight: {
+		Accent: "162", Error: "160", Border: "245", Faint: "243",
+	},
+	ThemeHighContrast: {
+		Accent: "11", Error: "9", Border: "15", Selection: "14", Faint: "250",
+	},
+}
+
+// BuiltinTheme looks a built-in theme up by name, ignoring case.
+func BuiltinTheme(name string) (Theme, bool) {
+	t, ok := builtinThemes[strings.ToLower(name)]
+	return t, ok
+}
+
+// DefaultTheme is ThemeDark, which the components use until they are given another theme.
+func DefaultTheme() Theme {
+	return builtinThemes[ThemeDark]
+}
+
+func (t Theme) AccentStyle() lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(t.Accent)
+}
+
+func (t Theme) ErrorStyle() lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(t.Error)
+}
+
+func (t Theme) FaintStyle() lipgloss.Style {
+	if t.Faint == "" {
+		return lipgloss.NewStyle().Faint(true)
+	}
+	return lipgloss.NewStyle().Foreground(t.Faint)
+}
+
+// SelectedStyle highlights the cursor row, in Selection reversed when it is set.
+func (t Theme) SelectedStyle() lipgloss.Style {
+	style := lipgloss.NewStyle().Reverse(true)
+	if t.Selection != "" {
+		style = style.Foreground(t.Selection)
+	}
+	return style
+}
+
+// BoxStyle is a bordered box in the Border color.
+func (t Theme) BoxStyle() lipgloss.Style {
+	style := lipgloss.NewStyle().Border(lipgloss.NormalBorder())
+	if t.Border != "" {
+		style = style.BorderForeground(t.Border)
+	}
+	return style
+}
@@ -1,17 +1,53 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	"codeberg.org/JoaoGarcia/Mezzotone/internal/app"
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/clipboard"
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/config"
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/export"
 	"codeberg.org/JoaoGarcia/Mezzotone/internal/services"
+	"codeberg.org/JoaoGarcia/Mezzotone/internal/termgfx"
+	"codeberg.org/JoaoGarcia/Mezzotone/pkg/ascii"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
 func main() {
+	// The flags show the built-in defaults, the config file is only read once they are parsed
+	// so a broken one cannot get in the way of -h.
+	defaults := config.DefaultSettings()
+	configPath, _ := config.Path()
+
 	debug := flag.Bool("debug", false, "enable debug logging")
+	preset := flag.String("preset", "", "start from a named preset of the config file; other render flags override it")
+	textSize := flag.Int("text-size", defaults.TextSize, "character cell width in pixels (non-interactive mode)")
+	fontAspect := flag.Float64("font-aspect", defaults.FontAspect, "character height ratio vs width (non-interactive mode)")
+	directionalRender := flag.Bool("directional", defaults.DirectionalRender, "use edge direction glyphs (non-interactive mode)")
+	edgeThreshold := flag.Float64("edge-threshold", defaults.EdgeThreshold, "edge cutoff 0..1 for directional glyphs (non-interactive mode)")
+	reverseChars := flag.Bool("reverse", defaults.ReverseChars, "invert ramp mapping (non-interactive mode)")
+	highContrast := flag.Bool("high-contrast", defaults.HighContrast, "apply stronger luminance contrast (non-interactive mode)")
+	runeMode := flag.String("rune-mode", defaults.RuneMode, "ramp preset: ASCII, UNICODE, DOTS, RECTANGLES, BARS, LOADING (non-interactive mode)")
+	outputFormat := flag.String("format", "text", "output format: text, json, svg, or for GIF input cast, replay or gif (non-interactive mode)")
+	crlf := flag.Bool("crlf", false, "end text rows with CRLF instead of LF (non-interactive mode)")
+	fontFamily := flag.String("font-family", export.DefaultSVGFontFamily, "CSS font stack for svg output (non-interactive mode)")
+	trimTrailing := flag.Bool("trim", false, "trim trailing whitespace from text rows (non-interactive mode)")
+	noMouse := flag.Bool("no-mouse", false, "leave the mouse to the terminal so the art can be selected natively (TUI)")
+	flag.Usage = func() {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [image|-]\n\n", os.Args[0])
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "Without arguments the interactive TUI starts.\n")
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "With an image path, or - for stdin, the art is written to stdout.\n")
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "Defaults and presets are read from %s.\n\n", configPath)
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 	if *debug {
 		err := services.InitLogger("logs.log")
@@ -20,10 +56,191 @@ func main() {
 		}
 	}
 
-	p := tea.NewProgram(app.NewMezzotoneModel(), tea.WithAltScreen())
+	// Without a config directory the built-in defaults apply and presets are not saved.
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		if *preset != "" {
+			_, _ = fmt.Fprintf(os.Stderr, "mezzotone: -preset %s: %v\n", *preset, err)
+			os.Exit(1)
+		}
+		// Load falls back to the built-in defaults. Presets are not saved, that would overwrite
+		// the broken file.
+		warn("%v; using the built-in defaults", err)
+		configPath = ""
+	}
+
+	settings := cfg.Defaults
+	if *preset != "" {
+		settings, err = cfg.Preset(*preset)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "mezzotone: %v (config %s)\n", err, configPath)
+			os.Exit(1)
+		}
+	}
+
+	if flag.NArg() > 0 {
+		// Render flags given on the command line win over the preset.
+		flag.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "text-size":
+				settings.TextSize = *textSize
+			case "font-aspect":
+				settings.FontAspect = *fontAspect
+			case "directional":
+				settings.DirectionalRender = *directionalRender
+			case "edge-threshold":
+				settings.EdgeThreshold = *edgeThreshold
+			case "reverse":
+				settings.ReverseChars = *reverseChars
+			case "high-contrast":
+				settings.HighContrast = *highContrast
+			case "rune-mode":
+				settings.RuneMode = *runeMode
+			}
+		})
+		options, err := settings.RenderOptions()
+		if err == nil {
+			lineEnding := ascii.LineEndingLF
+			if *crlf {
+				lineEnding = ascii.LineEndingCRLF
+			}
+			err = renderToWriter(
+				flag.Arg(0), *outputFormat, options, os.Stdin, os.Stdout,
+				[]export.SVGOption{export.WithSVGFontFamily(*fontFamily)},
+				ascii.WithLineEnding(lineEnding), ascii.WithTrimTrailingSpace(*trimTrailing),
+			)
+		}
+		if err != nil {
+			_ = services.Logger().Errorf("non-interactive render failed: %v", err)
+			_, _ = fmt.Fprintf(os.Stderr, "mezzotone: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	statePath, _ := config.StatePath()
+	state, err := config.LoadState(statePath)
+	if err != nil {
+		// The state is only a convenience, a broken file is replaced on exit.
+		_ = services.Logger().Errorf("loading session state failed: %v", err)
+	}
+
+	keys, err := app.NewKeyMap(cfg.Keys)
+	if err != nil {
+		warn("%v (config %s); using the default key bindings", err, configPath)
+		keys = app.DefaultKeyMap()
+	}
+
+	theme, err := app.ResolveTheme(cfg, lipgloss.HasDarkBackground)
+	if err != nil {
+		warn("%v (config %s); using the default theme", err, configPath)
+		theme, _ = app.ResolveTheme(config.Default(), lipgloss.HasDarkBackground)
+	}
+
+	model := app.NewMezzotoneModel()
+	model.SetKeyMap(keys)
+	model.SetTheme(theme)
+	model.SetConfig(cfg, configPath)
+	model.SetState(state)
+	if *preset != "" {
+		// Already looked up above, so this cannot fail.
+		_ = model.ApplyPreset(*preset)
+	}
+	model.SetGraphics(termgfx.Detect(os.Stdin, os.Stdout, 200*time.Millisecond))
+	// The OSC 52 sequence of a copy goes through the program's output, between its frames.
+	terminal := clipboard.NewTerminal(os.Stdout)
+	model.SetClipboard(clipboard.DefaultEnvironment(terminal))
+	programOptions := []tea.ProgramOption{tea.WithOutput(terminal), tea.WithAltScreen()}
+	if !*noMouse && !cfg.DisableMouse {
+		programOptions = append(programOptions, tea.WithMouseCellMotion())
+	}
+	p := tea.NewProgram(model, programOptions...)
 	if _, err := p.Run(); err != nil {
 		_ = services.Logger().Error("Unexpected Error. Unable to recover")
 		fmt.Printf("An unexpected error has occurred.\n")
 		os.Exit(1)
 	}
+	if statePath != "" {
+		if err := config.SaveState(statePath, model.SessionState()); err != nil {
+			_ = services.Logger().Errorf("saving session state failed: %v", err)
+		}
+	}
+}
+
+// warn reports a problem that Mezzotone works around, on stderr and in the debug log.
+func warn(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	_ = services.Logger().Errorf("%s", msg)
+	_, _ = fmt.Fprintf(os.Stderr, "mezzotone: %s\n", msg)
+}
+
+// renderToWriter converts input ("-" for stdin) and writes the art to out as text, json or svg,
+// or, for the animated formats, every GIF frame as an asciicast, replay script or GIF.
+func renderToWriter(
+	input string,
+	format string,
+	options services.RenderOptions,
+	stdin io.Reader,
+	out io.Writer,
+	svgOptions []export.SVGOption,
+	encoderOptions ...ascii.EncoderOption,
+) error {
+	if animatedFormat := export.Format(strings.ToUpper(format)); animatedFormat.IsAnimated() {
+		return renderAnimationToWriter(input, animatedFormat, options, stdin, out)
+	}
+	if format != "text" && format != "json" && format != "svg" {
+		return fmt.Errorf("invalid output format: %s", format)
+	}
+
+	var (
+		result *services.RenderResult
+		err    error
+	)
+	if input == "-" {
+		result, err = services.RenderReader(stdin, options)
+	} else {
+		result, err = services.RenderImage(input, options)
+	}
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	writer := bufio.NewWriter(out)
+	if format == "svg" {
+		if err := export.WriteSVG(writer, result, svgOptions...); err != nil {
+			return err
+		}
+		return writer.Flush()
+	}
+	if err := ascii.NewEncoder(writer, encoderOptions...).Encode(result.Grid); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+func renderAnimationToWriter(input string, format export.Format, options services.RenderOptions, stdin io.Reader, out io.Writer) error {
+	var (
+		animation *services.Animation
+		err       error
+	)
+	if input == "-" {
+		animation, err = services.RenderAnimationReader(stdin, options)
+	} else {
+		animation, err = services.RenderAnimation(input, options)
+	}
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(out)
+	if err := export.WriteAnimation(writer, animation, format); err != nil {
+		return err
+	}
+	return writer.Flush()
 }
@@ -0,0 +1,105 @@
+package ascii
+
+import (
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
+
+// ConvertReader decodes an image (PNG, JPEG, GIF, BMP, TIFF or WebP) from r and converts it.
+// The input is bounded by MaxInputBytes and MaxPixels, see Decode.
+func ConvertReader(r io.Reader, opts ...Option) (*Result, error) {
+	img, format, err := Decode(r, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := Convert(img, opts...)
+	if err != nil {
+		return nil, err
+	}
+	result.Format = format
+	return result, nil
+}
+
+// Convert turns img into a character grid using the given options.
+func Convert(img image.Image, opts ...Option) (*Result, error) {
+	options, err := NewOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := options.ValidateGrid(img.Bounds().Dx(), img.Bounds().Dy()); err != nil {
+		return nil, err
+	}
+
+	// Compute grid resolution (cols x rows) based on image size + character cell size.
+	cols, rows := getColsAndRows(img, options.TextSize, options.FontAspect)
+	cellWidth := float64(img.Bounds().Dx()) / float64(cols)
+	cellHeight := float64(img.Bounds().Dy()) / float64(rows)
+	if cellWidth <= 0 {
+		cellWidth = 1
+	}
+	if cellHeight <= 0 {
+		cellHeight = 1
+	}
+
+	grid := make([][]rune, rows)
+	for r := 0; r < rows; r++ {
+		grid[r] = make([]rune, cols)
+	}
+
+	// Build a luminance grid (rows x cols) where each cell is 0..1.
+	// Each cell luminance is computed by averaging pixels in the corresponding image region.
+	luminanceGrid, colors := buildLuminanceGrid(img, cols, rows, options.HighContrast)
+
+	edgeThreshold := 0.0
+	var edgeInfos [][]edgeInfo
+	edgeStats := EdgeStats{Directional: options.DirectionalRender}
+	if options.DirectionalRender {
+		edgeThreshold = clamp01(options.EdgeThreshold)
+		edgeStats.Threshold = edgeThreshold
+
+		dogGrid := differenceOfGaussiansGrid(luminanceGrid, 0.5, 1.0)
+		edgeInfos, edgeStats.HighestMagnitude = applySobelFilter(dogGrid, cellWidth, cellHeight)
+	}
+
+	ramp := Ramp(options.RuneMode, options.ReverseChars)
+
+	// Convert each luminance cell to a glyph using the chosen ramp.
+	// indices are [row][col] matching grid.
+	for i := 0; i < len(luminanceGrid); i++ {
+		for j := 0; j < len(luminanceGrid[i]); j++ {
+			//if directionalRender true and Magnitude surpasses threshold replace with directional char
+			if options.DirectionalRender && edgeInfos[i][j].Magnitude > edgeThreshold {
+				grid[i][j] = getEdgeRuneFromGradient(edgeInfos[i][j], options.RuneMode)
+				edgeStats.EdgeCells++
+				if grid[i][j] == ' ' {
+					grid[i][j] = runeForLuminance(ramp, luminanceGrid[i][j])
+				}
+			} else {
+				grid[i][j] = runeForLuminance(ramp, luminanceGrid[i][j])
+			}
+		}
+	}
+
+	return &Result{
+		Grid:         grid,
+		Colors:       colors,
+		Luminance:    luminanceGrid,
+		Cols:         cols,
+		Rows:         rows,
+		SourceWidth:  img.Bounds().Dx(),
+		SourceHeight: img.Bounds().Dy(),
+		CellWidth:    cellWidth,
+		CellHeight:   cellHeight,
+		Edges:        edgeStats,
+		Options:      options,
+	}, nil
+}
@@ -0,0 +1,167 @@
+package ascii_test
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/pkg/ascii"
+)
+
+func newGradientImage(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := uint8((x * 255) / (width - 1))
+			img.SetNRGBA(x, y, color.NRGBA{R: v, G: v, B: 255 - v, A: 255})
+		}
+	}
+	return img
+}
+
+func TestNewOptionsAppliesFunctionalOptionsOverDefaults(t *testing.T) {
+	opts, err := ascii.NewOptions(ascii.WithTextSize(4), ascii.WithRuneMode(ascii.RuneModeDots))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.TextSize != 4 || opts.RuneMode != ascii.RuneModeDots {
+		t.Fatalf("expected overrides to apply, got %+v", opts)
+	}
+	if opts.FontAspect != ascii.DefaultOptions().FontAspect {
+		t.Fatalf("expected untouched fields to keep defaults, got %v", opts.FontAspect)
+	}
+}
+
+func TestNewOptionsRejectsInvalidRuneMode(t *testing.T) {
+	if _, err := ascii.NewOptions(ascii.WithRuneMode("INVALID")); err == nil {
+		t.Fatalf("expected error for invalid rune mode")
+	}
+}
+
+func TestConvertReturnsGridMatchingDimensions(t *testing.T) {
+	img := newGradientImage(64, 40)
+
+	res, err := ascii.Convert(img, ascii.WithTextSize(8), ascii.WithFontAspect(2))
+	if err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+	if res.Cols != 8 || res.Rows != 3 {
+		t.Fatalf("expected 8x3 grid, got %dx%d", res.Cols, res.Rows)
+	}
+	if len(res.Grid) != res.Rows || len(res.Colors) != res.Rows {
+		t.Fatalf("grid and colors must have one entry per row")
+	}
+	for i := range res.Grid {
+		if len(res.Grid[i]) != res.Cols || len(res.Colors[i]) != res.Cols {
+			t.Fatalf("row %d does not have %d cells", i, res.Cols)
+		}
+	}
+	if res.SourceWidth != 64 || res.SourceHeight != 40 {
+		t.Fatalf("unexpected source size %dx%d", res.SourceWidth, res.SourceHeight)
+	}
+	if strings.Count(res.String(), "\n") != res.Rows {
+		t.Fatalf("expected one line per row, got %q", res.String())
+	}
+}
+
+func TestConvertReaderDecodesAndRecordsFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, newGradientImage(32, 32)); err != nil {
+		t.Fatalf("failed encoding fixture: %v", err)
+	}
+
+	res, err := ascii.ConvertReader(&buf, ascii.WithTextSize(4))
+	if err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+	if res.Format != "png" {
+		t.Fatalf("expected png format, got %q", res.Format)
+	}
+}
+
+func TestConvertReaderRejectsGarbage(t *testing.T) {
+	if _, err := ascii.ConvertReader(strings.NewReader("not an image")); err == nil {
+		t.Fatalf("expected decode error")
+	}
+}
+
+func TestDecodeEnforcesLimits(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, newGradientImage(32, 32)); err != nil {
+		t.Fatalf("failed encoding fixture: %v", err)
+	}
+	data := buf.Bytes()
+
+	t.Run("byte limit", func(t *testing.T) {
+		_, _, err := ascii.Decode(bytes.NewReader(data), ascii.WithLimits(int64(len(data)-1), 0))
+		if !errors.Is(err, ascii.ErrInputTooLarge) {
+			t.Fatalf("expected ErrInputTooLarge, got %v", err)
+		}
+	})
+
+	t.Run("pixel limit", func(t *testing.T) {
+		_, _, err := ascii.Decode(bytes.NewReader(data), ascii.WithLimits(0, 32*32-1))
+		if !errors.Is(err, ascii.ErrInputTooLarge) {
+			t.Fatalf("expected ErrInputTooLarge, got %v", err)
+		}
+	})
+
+	t.Run("within limits", func(t *testing.T) {
+		_, format, err := ascii.Decode(bytes.NewReader(data), ascii.WithLimits(int64(len(data)), 32*32))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if format != "png" {
+			t.Fatalf("expected png, got %q", format)
+		}
+	})
+}
+
+func TestDecodeReportsUnsupportedFormat(t *testing.T) {
+	_, _, err := ascii.Decode(strings.NewReader("plain text is not an image"))
+	if !errors.Is(err, ascii.ErrUnsupportedFormat) {
+		t.Fatalf("expected ErrUnsupportedFormat, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "text/plain") {
+		t.Fatalf("expected sniffed content type in error, got %v", err)
+	}
+}
+
+func TestValidateReportsEveryInvalidField(t *testing.T) {
+	_, err := ascii.NewOptions(
+		ascii.WithTextSize(-5),
+		ascii.WithFontAspect(0),
+		ascii.WithDirectionalRender(40),
+		ascii.WithRuneMode("NOPE"),
+	)
+
+	var validationErr *ascii.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	var fields []string
+	for _, field := range validationErr.Fields {
+		fields = append(fields, field.Field)
+	}
+	if strings.Join(fields, ",") != "textSize,fontAspect,edgeThreshold,runeMode" {
+		t.Fatalf("unexpected invalid fields %v", fields)
+	}
+}
+
+func TestConvertRejectsGridOverLimit(t *testing.T) {
+	img := newGradientImage(100, 100)
+
+	_, err := ascii.Convert(img, ascii.WithTextSize(1), ascii.WithFontAspect(1), ascii.WithMaxGridCells(100*100-1))
+	var validationErr *ascii.ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Fields[0].Field != "textSize" {
+		t.Fatalf("expected a textSize ValidationError, got %v", err)
+	}
+
+	if _, err := ascii.Convert(img, ascii.WithTextSize(1), ascii.WithFontAspect(1), ascii.WithMaxGridCells(100*100)); err != nil {
+		t.Fatalf("unexpected error at the limit: %v", err)
+	}
+}
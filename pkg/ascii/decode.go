@@ -0,0 +1,61 @@
+package ascii
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+)
+
+var (
+	// ErrUnsupportedFormat is returned when the input is not an image any registered decoder understands.
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	// ErrInputTooLarge is returned when the input exceeds MaxInputBytes or MaxPixels.
+	ErrInputTooLarge = errors.New("image exceeds size limit")
+)
+
+// Decode reads an image from r, sniffing its format and enforcing the limits in the given options.
+// It returns the decoded image and the decoder name ("png", "jpeg", ...).
+func Decode(r io.Reader, opts ...Option) (image.Image, string, error) {
+	options, err := NewOptions(opts...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if options.MaxInputBytes > 0 {
+		r = io.LimitReader(r, options.MaxInputBytes+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", err
+	}
+	if options.MaxInputBytes > 0 && int64(len(data)) > options.MaxInputBytes {
+		return nil, "", fmt.Errorf("%w: input is larger than %d bytes", ErrInputTooLarge, options.MaxInputBytes)
+	}
+	if len(data) == 0 {
+		return nil, "", fmt.Errorf("%w: empty input", ErrUnsupportedFormat)
+	}
+
+	// DecodeConfig only reads the header, so dimensions are checked before allocating pixels.
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return nil, "", fmt.Errorf("%w: input looks like %s", ErrUnsupportedFormat, http.DetectContentType(data))
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if options.MaxPixels > 0 && config.Width*config.Height > options.MaxPixels {
+		return nil, "", fmt.Errorf(
+			"%w: %dx%d %s is more than %d pixels",
+			ErrInputTooLarge, config.Width, config.Height, format, options.MaxPixels,
+		)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	return img, format, nil
+}
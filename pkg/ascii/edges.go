@@ -0,0 +1,252 @@
+package ascii
+
+import "math"
+
+// edgeInfo Struct to store edge info from Sobel filter
+type edgeInfo struct {
+	Magnitude float64
+	Angle     float64
+}
+
+/*
+Applies Sobel filter to lumaGrid
+
+	Searches for biggest Change in luminance in adjacent grid values and calculates magnitude and angle of the change
+	Returns edgeInfo grid with normalized values and the highest raw magnitude used for normalization
+
+Ref: https://stackoverflow.com/questions/17815687/image-processing-implementing-sobel-filter
+*/
+func applySobelFilter(luminanceGrid [][]float64, cellWidth, cellHeight float64) ([][]edgeInfo, float64) {
+	rows := len(luminanceGrid)
+	if rows == 0 {
+		return nil, 0
+	}
+	cols := len(luminanceGrid[0])
+	if cols == 0 {
+		return nil, 0
+	}
+
+	edgeInfos := make([][]edgeInfo, rows)
+	for y := 0; y < rows; y++ {
+		edgeInfos[y] = make([]edgeInfo, cols)
+	}
+
+	sobelX := [][]int{
+		{-1, 0, 1},
+		{-2, 0, 2},
+		{-1, 0, 1},
+	}
+	sobelY := [][]int{
+		{-1, -2, -1},
+		{0, 0, 0},
+		{1, 2, 1},
+	}
+
+	//store highest value for percentile normalization
+	var highestMagnitude float64 = 0
+	invCellWidth := 1.0
+	invCellHeight := 1.0
+	if cellWidth > 0 {
+		invCellWidth = 1.0 / cellWidth
+	}
+	if cellHeight > 0 {
+		invCellHeight = 1.0 / cellHeight
+	}
+
+	for y := 1; y < rows-1; y++ {
+		for x := 1; x < cols-1; x++ {
+
+			Gx :=
+				(float64(sobelX[0][0]) * luminanceGrid[y-1][x-1]) +
+					(float64(sobelX[0][1]) * luminanceGrid[y-1][x]) +
+					(float64(sobelX[0][2]) * luminanceGrid[y-1][x+1]) +
+					(float64(sobelX[1][0]) * luminanceGrid[y][x-1]) +
+					(float64(sobelX[1][1]) * luminanceGrid[y][x]) +
+					(float64(sobelX[1][2]) * luminanceGrid[y][x+1]) +
+					(float64(sobelX[2][0]) * luminanceGrid[y+1][x-1]) +
+					(float64(sobelX[2][1]) * luminanceGrid[y+1][x]) +
+					(float64(sobelX[2][2]) * luminanceGrid[y+1][x+1])
+
+			Gy :=
+				(float64(sobelY[0][0]) * luminanceGrid[y-1][x-1]) +
+					(float64(sobelY[0][1]) * luminanceGrid[y-1][x]) +
+					(float64(sobelY[0][2]) * luminanceGrid[y-1][x+1]) +
+					(float64(sobelY[1][0]) * luminanceGrid[y][x-1]) +
+					(float64(sobelY[1][1]) * luminanceGrid[y][x]) +
+					(float64(sobelY[1][2]) * luminanceGrid[y][x+1]) +
+					(float64(sobelY[2][0]) * luminanceGrid[y+1][x-1]) +
+					(float64(sobelY[2][1]) * luminanceGrid[y+1][x]) +
+					(float64(sobelY[2][2]) * luminanceGrid[y+1][x+1])
+
+			Gx = Gx * invCellWidth
+			Gy = Gy * invCellHeight
+			magnitude := math.Sqrt(Gx*Gx + Gy*Gy)
+			angle := math.Atan2(Gy, Gx)
+
+			edgeInfos[y][x] = edgeInfo{
+				Magnitude: magnitude,
+				Angle:     angle,
+			}
+
+			if magnitude > highestMagnitude {
+				highestMagnitude = magnitude
+			}
+		}
+	}
+
+	if highestMagnitude < 0.01 {
+		highestMagnitude = 0.01
+	}
+
+	//normalize Values to 0..1
+	for y := 0; y < len(edgeInfos); y++ {
+		for x := 0; x < len(edgeInfos[y]); x++ {
+			edgeInfos[y][x].Magnitude = edgeInfos[y][x].Magnitude / highestMagnitude
+		}
+	}
+
+	return edgeInfos, highestMagnitude
+}
+
+// Get Rune if directionalRender is true intead of using luminance value
+func getEdgeRuneFromGradient(edge edgeInfo, runeMode RuneMode) rune {
+	// Sobel angle is gradient direction;
+	// edge orientation is perpendicular.
+	angle := edge.Angle + (math.Pi / 2)
+	if angle < 0 {
+		angle += 2 * math.Pi
+	}
+
+	// Normalize into 0..Pi (edges are symmetric — 0 and Pi are the same edge direction)
+	if angle >= math.Pi {
+		angle -= math.Pi
+	}
+
+	if runeMode != RuneModeASCII {
+		switch {
+		case angle < math.Pi/8 || angle >= 7*math.Pi/8:
+			return '─'
+		case angle < 3*math.Pi/8:
+			return '╲'
+		case angle < 5*math.Pi/8:
+			return '│'
+		default:
+			return '╱'
+		}
+	}
+
+	switch {
+	case angle < math.Pi/8 || angle >= 7*math.Pi/8:
+		return '-'
+	case angle < 3*math.Pi/8:
+		return '\\'
+	case angle < 5*math.Pi/8:
+		return '|'
+	default:
+		return '/'
+	}
+}
+
+// Apply difference fo Gaussians to help with edge detections
+func differenceOfGaussiansGrid(luminanceGrid [][]float64, sigma1, sigma2 float64) [][]float64 {
+	rows := len(luminanceGrid)
+	if rows == 0 {
+		return nil
+	}
+	cols := len(luminanceGrid[0])
+	if cols == 0 {
+		return nil
+	}
+
+	if sigma1 <= 0 {
+		sigma1 = 0.6
+	}
+	if sigma2 <= sigma1 {
+		sigma2 = sigma1 * 2
+	}
+
+	clampInt := func(x, lo, hi int) int {
+		if x < lo {
+			return lo
+		}
+		if x > hi {
+			return hi
+		}
+		return x
+	}
+
+	gaussianKernel1D := func(sigma float64) ([]float64, int) {
+		if sigma <= 0 {
+			return []float64{1}, 0
+		}
+		radius := int(math.Ceil(3 * sigma))
+		size := 2*radius + 1
+
+		k := make([]float64, size)
+		var sum float64
+		twoSigma2 := 2 * sigma * sigma
+
+		for i := -radius; i <= radius; i++ {
+			x := float64(i)
+			v := math.Exp(-(x * x) / twoSigma2)
+			k[i+radius] = v
+			sum += v
+		}
+
+		if sum < 1e-12 {
+			sum = 1e-12
+		}
+		for i := range k {
+			k[i] /= sum
+		}
+
+		return k, radius
+	}
+
+	gaussianBlur := func(grid [][]float64, sigma float64) [][]float64 {
+		k, r := gaussianKernel1D(sigma)
+
+		// horizontal pass
+		tmp := make([][]float64, rows)
+		for y := 0; y < rows; y++ {
+			tmp[y] = make([]float64, cols)
+			for x := 0; x < cols; x++ {
+				sum := 0.0
+				for i := -r; i <= r; i++ {
+					xx := clampInt(x+i, 0, cols-1)
+					sum += grid[y][xx] * k[i+r]
+				}
+				tmp[y][x] = sum
+			}
+		}
+
+		// vertical pass
+		out := make([][]float64, rows)
+		for y := 0; y < rows; y++ {
+			out[y] = make([]float64, cols)
+			for x := 0; x < cols; x++ {
+				sum := 0.0
+				for i := -r; i <= r; i++ {
+					yy := clampInt(y+i, 0, rows-1)
+					sum += tmp[yy][x] * k[i+r]
+				}
+				out[y][x] = sum
+			}
+		}
+
+		return out
+	}
+
+	// compute DoG = blur(sigma1) - blur(sigma2)
+	g1 := gaussianBlur(luminanceGrid, sigma1)
+	g2 := gaussianBlur(luminanceGrid, sigma2)
+
+	dog := make([][]float64, rows)
+	for y := 0; y < rows; y++ {
+		dog[y] = make([]float64, cols)
+		for x := 0; x < cols; x++ {
+			dog[y][x] = g1[y][x] - g2[y][x]
+		}
+	}
+	return dog
+}
@@ -0,0 +1,88 @@
+package ascii
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	LineEndingLF   = "\n"
+	LineEndingCRLF = "\r\n"
+)
+
+// Encoder streams a rune grid to an io.Writer one row at a time.
+type Encoder struct {
+	w                 io.Writer
+	lineEnding        string
+	trimTrailingSpace bool
+	buf               []byte
+}
+
+// EncoderOption configures an Encoder.
+type EncoderOption func(*Encoder)
+
+// WithLineEnding sets the sequence written after every row (LineEndingLF by default).
+func WithLineEnding(lineEnding string) EncoderOption {
+	return func(e *Encoder) { e.lineEnding = lineEnding }
+}
+
+// WithTrimTrailingSpace drops trailing spaces from every row before writing it.
+func WithTrimTrailingSpace(trim bool) EncoderOption {
+	return func(e *Encoder) { e.trimTrailingSpace = trim }
+}
+
+func NewEncoder(w io.Writer, opts ...EncoderOption) *Encoder {
+	e := &Encoder{w: w, lineEnding: LineEndingLF}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(e)
+		}
+	}
+	return e
+}
+
+// Encode writes every row of grid followed by the configured line ending.
+func (e *Encoder) Encode(grid [][]rune) error {
+	for _, row := range grid {
+		if err := e.WriteRow(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteRow writes a single row followed by the configured line ending.
+func (e *Encoder) WriteRow(row []rune) error {
+	if e.trimTrailingSpace {
+		end := len(row)
+		for end > 0 && row[end-1] == ' ' {
+			end--
+		}
+		row = row[:end]
+	}
+
+	// Reuse one buffer across rows so large grids do not allocate per row.
+	e.buf = e.buf[:0]
+	for _, r := range row {
+		e.buf = utf8.AppendRune(e.buf, r)
+	}
+	e.buf = append(e.buf, e.lineEnding...)
+
+	_, err := e.w.Write(e.buf)
+	return err
+}
+
+// EncodeToString is the in-memory path of Encoder backed by a strings.Builder.
+func EncodeToString(grid [][]rune, opts ...EncoderOption) string {
+	var b strings.Builder
+	size := 0
+	for _, row := range grid {
+		size += len(row) + 1
+	}
+	b.Grow(size)
+
+	// strings.Builder never returns a write error.
+	_ = NewEncoder(&b, opts...).Encode(grid)
+	return b.String()
+}
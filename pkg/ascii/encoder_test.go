@@ -0,0 +1,39 @@
+package ascii_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"codeberg.org/JoaoGarcia/Mezzotone/pkg/ascii"
+)
+
+func TestEncodeToStringDefaultsToLF(t *testing.T) {
+	got := ascii.EncodeToString([][]rune{[]rune("ab "), []rune("█▓")})
+	if got != "ab \n█▓\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+}
+
+func TestEncoderLineEndingAndTrim(t *testing.T) {
+	var buf bytes.Buffer
+	enc := ascii.NewEncoder(&buf, ascii.WithLineEnding(ascii.LineEndingCRLF), ascii.WithTrimTrailingSpace(true))
+
+	if err := enc.Encode([][]rune{[]rune("a b  "), []rune("   "), []rune("c")}); err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	if got := buf.String(); got != "a b\r\n\r\nc\r\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestEncoderPropagatesWriteErrors(t *testing.T) {
+	err := ascii.NewEncoder(failingWriter{}).Encode([][]rune{[]rune("x")})
+	if err == nil {
+		t.Fatalf("expected write error to be returned")
+	}
+}
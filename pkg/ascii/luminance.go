@@ -0,0 +1,165 @@
+package ascii
+
+import (
+	"image"
+	"image/color"
+)
+
+// Calculates Columns and Rows for given TextSize and FontAspect
+func getColsAndRows(img image.Image, textSize int, fontAspect float64) (cols, rows int) {
+	b := img.Bounds()
+	return gridSize(b.Dx(), b.Dy(), textSize, fontAspect)
+}
+
+func gridSize(imgW, imgH, textSize int, fontAspect float64) (cols, rows int) {
+	charW := textSize
+	charH := int(float64(textSize) * fontAspect)
+	if charW <= 0 {
+		charW = 8
+	}
+	if charH <= 0 {
+		charH = 16
+	}
+
+	cols = (imgW + charW - 1) / charW
+	rows = (imgH + charH - 1) / charH
+
+	if cols < 1 {
+		cols = 1
+	}
+	if rows < 1 {
+		rows = 1
+	}
+
+	return cols, rows
+}
+
+// Builds a grid of averaged luminance values in [0..1] and the averaged color of every cell.
+func buildLuminanceGrid(inputImg image.Image, cols, rows int, highContrast bool) ([][]float64, [][]color.RGBA) {
+
+	imgBounds := inputImg.Bounds()
+	imgWidth, imgHeight := imgBounds.Dx(), imgBounds.Dy()
+
+	cellWidth := imgWidth / cols
+	cellHeight := imgHeight / rows
+
+	// Safety fallback if cols/rows are weird (should be prevented earlier).
+	if cellWidth <= 0 {
+		cellWidth = 8
+	}
+	if cellHeight <= 0 {
+		cellHeight = 16
+	}
+
+	// Allocate luminance and color grids.
+	grid := make([][]float64, rows)
+	colors := make([][]color.RGBA, rows)
+	for gridRow := 0; gridRow < rows; gridRow++ {
+		grid[gridRow] = make([]float64, cols)
+		colors[gridRow] = make([]color.RGBA, cols)
+	}
+
+	for gridRow := 0; gridRow < rows; gridRow++ {
+		// Pixel Y-range for this grid row.
+		cellRowPixelStartY := gridRow * cellHeight
+		cellRowPixelEndY := cellRowPixelStartY + cellHeight
+		if cellRowPixelStartY >= imgHeight {
+			cellRowPixelStartY = imgHeight
+		}
+		if cellRowPixelEndY > imgHeight {
+			cellRowPixelEndY = imgHeight
+		}
+
+		for gridCol := 0; gridCol < cols; gridCol++ {
+			// Pixel X-range for this grid column.
+			cellColPixelStartX := gridCol * cellWidth
+			cellColPixelEndX := cellColPixelStartX + cellWidth
+			if cellColPixelStartX >= imgWidth {
+				cellColPixelStartX = imgWidth
+			}
+			if cellColPixelEndX > imgWidth {
+				cellColPixelEndX = imgWidth
+			}
+
+			// Fallback guard (should not happen if dimensions are sane).
+			if cellColPixelEndX <= cellColPixelStartX || cellRowPixelEndY <= cellRowPixelStartY {
+				grid[gridRow][gridCol] = 0
+				colors[gridRow][gridCol] = color.RGBA{A: 255}
+				continue
+			}
+
+			var lumaSum float64
+			var redSum, greenSum, blueSum float64
+			var sampleCount float64
+
+			for y := cellRowPixelStartY; y < cellRowPixelEndY; y++ {
+				for x := cellColPixelStartX; x < cellColPixelEndX; x++ {
+					c := color.NRGBAModel.Convert(
+						inputImg.At(imgBounds.Min.X+x, imgBounds.Min.Y+y),
+					).(color.NRGBA)
+
+					// Skip mostly transparent pixels to prevent background bleed.
+					if c.A < 10 {
+						continue
+					}
+
+					// Luminance is computed as 0..1.
+					pixelLuminance := calculateLuminance(c.R, c.G, c.B)
+					lumaSum += pixelLuminance
+					redSum += float64(c.R)
+					greenSum += float64(c.G)
+					blueSum += float64(c.B)
+					sampleCount++
+				}
+			}
+
+			// Average luminance;
+			// if all transparent, treat as black.
+			var cellLuma float64
+			cellColor := color.RGBA{A: 255}
+			if sampleCount > 0 {
+				cellLuma = lumaSum / sampleCount
+				cellColor.R = uint8(redSum / sampleCount)
+				cellColor.G = uint8(greenSum / sampleCount)
+				cellColor.B = uint8(blueSum / sampleCount)
+			}
+
+			// Optional contrast remap
+			if highContrast {
+				cellLuma = applyContrast(cellLuma, 1.7)
+			}
+
+			grid[gridRow][gridCol] = clamp01(cellLuma)
+			colors[gridRow][gridCol] = cellColor
+		}
+	}
+
+	return grid, colors
+}
+
+/*
+Calculates luminance from rgb values and normalizes them from 0..255 into 0..1
+
+	Uses standard relative luminance weights (Rec.709 / sRGB), where green contributes the most to perceived brightness
+*/
+func calculateLuminance(red uint8, green uint8, blue uint8) float64 {
+	luminance := 0.2126*float64(red) + 0.7152*float64(green) + 0.0722*float64(blue)
+	return luminance / 255.0
+}
+
+// Clamp to [0..1] to keep mapping stable.
+func clamp01(x float64) float64 {
+
+	if x < 0 {
+		return 0
+	}
+	if x > 1 {
+		return 1
+	}
+	return x
+}
+
+// Applies contrast to lumiance levels with contrast curve at 0.5
+func applyContrast(l float64, factor float64) float64 {
+	return clamp01((l-0.5)*factor + 0.5)
+}
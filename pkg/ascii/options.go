@@ -0,0 +1,229 @@
+package ascii
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// RuneMode selects the glyph ramp used to map luminance into characters.
+type RuneMode string
+
+const (
+	RuneModeASCII      RuneMode = "ASCII"
+	RuneModeUnicode    RuneMode = "UNICODE"
+	RuneModeDots       RuneMode = "DOTS"
+	RuneModeRectangles RuneMode = "RECTANGLES"
+	RuneModeBars       RuneMode = "BARS"
+	RuneModeLoading    RuneMode = "LOADING"
+)
+
+// RuneModes returns every supported RuneMode in display order.
+func RuneModes() []RuneMode {
+	return []RuneMode{RuneModeASCII, RuneModeUnicode, RuneModeDots, RuneModeRectangles, RuneModeBars, RuneModeLoading}
+}
+
+// Options controls how an image is converted into a character grid.
+// Build it with NewOptions and the With* functional options.
+type Options struct {
+	// TextSize roughly controls how many pixels map to one character horizontally.
+	TextSize int
+	// FontAspect is the character height ratio vs width; vertical cell size is TextSize * FontAspect.
+	FontAspect float64
+	// DirectionalRender places oriented glyphs on cells whose edge magnitude exceeds EdgeThreshold.
+	DirectionalRender bool
+	EdgeThreshold     float64
+	// ReverseChars inverts the ramp direction (useful for dark terminals / preference).
+	ReverseChars bool
+	// HighContrast applies a contrast curve after cell luminance averaging.
+	HighContrast bool
+	RuneMode     RuneMode
+
+	// MaxInputBytes caps how much ConvertReader reads from its source; 0 disables the limit.
+	MaxInputBytes int64
+	// MaxPixels caps the decoded image area (width * height); 0 disables the limit.
+	MaxPixels int
+	// MaxGridCells caps the output grid (cols * rows); 0 disables the limit.
+	MaxGridCells int
+}
+
+const (
+	DefaultMaxInputBytes int64 = 64 << 20
+	DefaultMaxPixels           = 64_000_000
+	DefaultMaxGridCells        = 1_000_000
+)
+
+// Bounds checked by Validate. Characters are practically never wider than tall or more than
+// MaxFontAspect times taller.
+const (
+	MinFontAspect = 0.25
+	MaxFontAspect = 8.0
+)
+
+// Option mutates Options while they are being built.
+type Option func(*Options)
+
+// DefaultOptions returns the same defaults the Mezzotone TUI starts with.
+func DefaultOptions() Options {
+	return Options{
+		TextSize:          10,
+		FontAspect:        2.3,
+		DirectionalRender: false,
+		EdgeThreshold:     0.6,
+		ReverseChars:      true,
+		HighContrast:      true,
+		RuneMode:          RuneModeASCII,
+		MaxInputBytes:     DefaultMaxInputBytes,
+		MaxPixels:         DefaultMaxPixels,
+		MaxGridCells:      DefaultMaxGridCells,
+	}
+}
+
+// NewOptions applies opts on top of DefaultOptions and validates the result.
+func NewOptions(opts ...Option) (Options, error) {
+	o := DefaultOptions()
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	if err := o.Validate(); err != nil {
+		return Options{}, err
+	}
+	return o, nil
+}
+
+// FieldError is one invalid option. Field is the option's camelCase name, e.g. "textSize".
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// ValidationError lists every invalid option, so all of them can be fixed at once.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Error()
+	}
+	return "invalid options: " + strings.Join(messages, "; ")
+}
+
+// Add records a problem with field.
+func (e *ValidationError) Add(field, format string, args ...any) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Err returns e when it holds any field errors and nil otherwise.
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Validate reports whether the options can be used for a conversion. The returned error is a
+// *ValidationError naming every invalid field.
+func (o Options) Validate() error {
+	var errs ValidationError
+	if o.TextSize < 1 {
+		errs.Add("textSize", "must be at least 1")
+	}
+	// Written as negated ranges so NaN is rejected too.
+	if !(o.FontAspect >= MinFontAspect && o.FontAspect <= MaxFontAspect) {
+		errs.Add("fontAspect", "must be between %g and %g", MinFontAspect, MaxFontAspect)
+	}
+	if !(o.EdgeThreshold >= 0 && o.EdgeThreshold <= 1) {
+		errs.Add("edgeThreshold", "must be between 0 and 1")
+	}
+	if !slices.Contains(RuneModes(), o.RuneMode) {
+		errs.Add("runeMode", "must be one of %s, got %q", joinRuneModes(), o.RuneMode)
+	}
+	if o.MaxInputBytes < 0 {
+		errs.Add("maxInputBytes", "must not be negative")
+	}
+	if o.MaxPixels < 0 {
+		errs.Add("maxPixels", "must not be negative")
+	}
+	if o.MaxGridCells < 0 {
+		errs.Add("maxGridCells", "must not be negative")
+	}
+	return errs.Err()
+}
+
+// ValidateGrid checks the grid a width x height image would produce against MaxGridCells.
+func (o Options) ValidateGrid(width, height int) error {
+	if o.MaxGridCells <= 0 {
+		return nil
+	}
+	cols, rows := gridSize(width, height, o.TextSize, o.FontAspect)
+	if cols*rows <= o.MaxGridCells {
+		return nil
+	}
+	var errs ValidationError
+	errs.Add("textSize", "gives a %dx%d grid for this %dx%d image, over the %d cell limit; increase it",
+		cols, rows, width, height, o.MaxGridCells)
+	return errs.Err()
+}
+
+func joinRuneModes() string {
+	modes := make([]string, 0, len(RuneModes()))
+	for _, mode := range RuneModes() {
+		modes = append(modes, string(mode))
+	}
+	return strings.Join(modes, ", ")
+}
+
+// WithOptions replaces every field with the given Options.
+func WithOptions(src Options) Option {
+	return func(o *Options) { *o = src }
+}
+
+func WithTextSize(textSize int) Option {
+	return func(o *Options) { o.TextSize = textSize }
+}
+
+func WithFontAspect(fontAspect float64) Option {
+	return func(o *Options) { o.FontAspect = fontAspect }
+}
+
+// WithDirectionalRender enables edge-aware glyphs for edges stronger than threshold (0..1).
+func WithDirectionalRender(threshold float64) Option {
+	return func(o *Options) {
+		o.DirectionalRender = true
+		o.EdgeThreshold = threshold
+	}
+}
+
+func WithReverseChars(reverse bool) Option {
+	return func(o *Options) { o.ReverseChars = reverse }
+}
+
+func WithHighContrast(highContrast bool) Option {
+	return func(o *Options) { o.HighContrast = highContrast }
+}
+
+func WithRuneMode(mode RuneMode) Option {
+	return func(o *Options) { o.RuneMode = mode }
+}
+
+// WithMaxGridCells bounds the output grid; zero disables the limit.
+func WithMaxGridCells(maxGridCells int) Option {
+	return func(o *Options) { o.MaxGridCells = maxGridCells }
+}
+
+// WithLimits bounds how many bytes ConvertReader may read and how many pixels a decoded image may have.
+// Zero disables the corresponding limit.
+func WithLimits(maxInputBytes int64, maxPixels int) Option {
+	return func(o *Options) {
+		o.MaxInputBytes = maxInputBytes
+		o.MaxPixels = maxPixels
+	}
+}
@@ -0,0 +1,60 @@
+package ascii
+
+// Dark to Bright
+const asciiRampDarkToBrightStr = "$@B%8&WM#*oahkbdpqwmZO0QLCJUYXzcvunxrjtf()1{}[]?_+~<>i!lI;:,^`. "
+const unicodeRampDarkToBrightStr = "█▓▒░■□@&%$#*+=~:;!,\".^`' "
+const dotsRampDarkToBrightStr = "●∙•· "
+const rectanglesRampDarkToBrightStr = "█▓▒░ "
+const barsRampDarkToBrightStr = "█▇▆▅▄▃▂▁ "
+const loadingRampDarkToBrightStr = "⣿⣷⣧⣇⣆⣄⣀ "
+
+// Bright to Dark
+const asciiRampBrightToDarkStr = " .`^,:;Il!i><~+_?][}{1)(ftjrxnuvczXYUJCLQ0OZmwqpdbkhao*#MW&8%B@$"
+const unicodeRampBrightToDarkStr = " '`^.\",!;:~=+*#$%&@□■░▒▓█"
+const dotsRampBrightToDarkStr = " ·•∙●"
+const rectanglesRampBrightToDarkStr = " ░▒▓█"
+const barsRampBrightToDarkStr = " ▁▂▃▄▅▆▇█"
+const loadingRampBrightToDarkStr = " ⣀⣄⣆⣇⣧⣷⣿"
+
+// Ramp returns the glyph ramp for mode, ordered as it is indexed by luminance.
+func Ramp(mode RuneMode, reverseChars bool) []rune {
+	switch mode {
+	case RuneModeUnicode:
+		if reverseChars {
+			return []rune(unicodeRampBrightToDarkStr)
+		}
+		return []rune(unicodeRampDarkToBrightStr)
+	case RuneModeDots:
+		if reverseChars {
+			return []rune(dotsRampBrightToDarkStr)
+		}
+		return []rune(dotsRampDarkToBrightStr)
+	case RuneModeRectangles:
+		if reverseChars {
+			return []rune(rectanglesRampBrightToDarkStr)
+		}
+		return []rune(rectanglesRampDarkToBrightStr)
+	case RuneModeBars:
+		if reverseChars {
+			return []rune(barsRampBrightToDarkStr)
+		}
+		return []rune(barsRampDarkToBrightStr)
+	case RuneModeLoading:
+		if reverseChars {
+			return []rune(loadingRampBrightToDarkStr)
+		}
+		return []rune(loadingRampDarkToBrightStr)
+	default:
+		if reverseChars {
+			return []rune(asciiRampBrightToDarkStr)
+		}
+		return []rune(asciiRampDarkToBrightStr)
+	}
+}
+
+// Get the rune correspondent to luminance in selected ramp
+func runeForLuminance(ramp []rune, luminance float64) rune {
+	// Map luminance to an index in the ramp:
+	index := int(clamp01(luminance) * float64(len(ramp)-1))
+	return ramp[index]
+}
@@ -0,0 +1,43 @@
+package ascii
+
+import "image/color"
+
+// Result is a converted image: a Rows x Cols grid of glyphs plus the data used to produce it.
+type Result struct {
+	// Grid holds the glyphs indexed as [row][col].
+	Grid [][]rune
+	// Colors holds the averaged source color of every cell, indexed like Grid.
+	Colors [][]color.RGBA
+	// Luminance holds the 0..1 cell luminance after the optional contrast curve, indexed like Grid.
+	Luminance [][]float64
+
+	Cols, Rows int
+	// SourceWidth and SourceHeight are the decoded image dimensions in pixels.
+	SourceWidth, SourceHeight int
+	// CellWidth and CellHeight are the source pixels covered by one character.
+	CellWidth, CellHeight float64
+	// Format is the decoder name ("png", "jpeg", ...) when the image was read by ConvertReader.
+	Format string
+
+	Edges   EdgeStats
+	Options Options
+}
+
+// EdgeStats summarizes the edge detection pass of a directional render.
+type EdgeStats struct {
+	Directional bool
+	// Threshold is the clamped magnitude cutoff that was applied.
+	Threshold float64
+	// HighestMagnitude is the raw Sobel magnitude used to normalize edges to 0..1.
+	HighestMagnitude float64
+	// EdgeCells counts the cells that received a directional glyph.
+	EdgeCells int
+}
+
+// String joins the grid rows, ending every row with a newline.
+func (r *Result) String() string {
+	if r == nil {
+		return ""
+	}
+	return EncodeToString(r.Grid)
+}